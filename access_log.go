@@ -0,0 +1,122 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/gin-gonic/gin.v1"
+)
+
+// AccessLogConfig configures access logging. Every request is logged in one line
+// via ILogger set on API, no access log is written when there's no logger.
+type AccessLogConfig struct {
+	// Disabled turns access logging off.
+	Disabled bool
+
+	// StatusLevels maps status class, e.g. 4 for 4xx responses, to log level.
+	// By default 5xx responses are logged as errors, 4xx as warnings
+	// and the rest as info.
+	StatusLevels map[int]LogLevel
+
+	// SkipRoutes is a list of route paths, as they were mapped, which aren't logged
+	// e.g. "/health". Paths of requests which don't match any route are checked too.
+	SkipRoutes []string
+}
+
+// defStatusLevels are default log levels of status classes.
+var defStatusLevels = map[int]LogLevel{
+	2: InfoLevel,
+	3: InfoLevel,
+	4: WarnLevel,
+	5: ErrorLevel,
+}
+
+// SetAccessLog configures access logging.
+func (api *API) SetAccessLog(config AccessLogConfig) {
+	api.accessLog = config
+}
+
+// logAccess is gin middleware which logs every request after it's handled.
+func (api *API) logAccess(innerContext *gin.Context) {
+	start := time.Now()
+	innerContext.Next()
+	if api.logger == nil || api.accessLog.Disabled {
+		return
+	}
+
+	routePath := innerContext.Request.URL.Path
+	successful := "-"
+	requestID := "-"
+	if value, exists := innerContext.Get(requestContextKey); exists {
+		request := value.(*Request)
+		requestID = request.ID()
+		if request.route != nil {
+			routePath = request.route.Path
+		}
+		if request.response != nil {
+			successful = strconv.FormatBool(request.response.Successful)
+		}
+	}
+	if api.skipAccessLog(routePath) {
+		return
+	}
+
+	status := innerContext.Writer.Status()
+	bytes := innerContext.Writer.Size()
+	if bytes < 0 {
+		bytes = 0
+	}
+	fields := []interface{}{
+		"method", innerContext.Request.Method,
+		"route", routePath,
+		"status", status,
+		"latency", time.Since(start),
+		"bytes", bytes,
+		"ip", innerContext.ClientIP(),
+		"request_id", requestID,
+		"successful", successful,
+	}
+	level := api.accessLogLevel(status)
+	if structuredLogger, ok := api.logger.(StructuredLogger); ok {
+		structuredLogger.Log(level, "Request handled", fields...)
+		return
+	}
+	logf(api.logger, level, formatFields(fields))
+}
+
+// formatFields formats key/value pairs as "key=value" separated by spaces.
+// Percent signs are escaped so result can be used as format string.
+func formatFields(fields []interface{}) string {
+	pairs := make([]string, 0, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%v=%v", fields[i], fields[i+1]))
+	}
+	return strings.Replace(strings.Join(pairs, " "), "%", "%%", -1)
+}
+
+func (api *API) skipAccessLog(routePath string) bool {
+	for _, skipped := range api.accessLog.SkipRoutes {
+		if skipped == routePath {
+			return true
+		}
+	}
+	return false
+}
+
+// accessLogLevel returns log level of response status.
+func (api *API) accessLogLevel(status int) LogLevel {
+	statusClass := status / 100
+	if level, ok := api.accessLog.StatusLevels[statusClass]; ok {
+		return level
+	}
+	if level, ok := defStatusLevels[statusClass]; ok {
+		return level
+	}
+	return InfoLevel
+}
@@ -0,0 +1,55 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates API with logger and checks access log lines of successful,
+// failed and unmatched requests.
+func TestAccessLog(t *testing.T) {
+	api, handlers, http := newAPITest()
+	logger := &testLogger{}
+	api.SetLogger(logger)
+	api.Map("get", "/users/:id", handlers.emptyHandler)
+	api.Map("get", "/forbidden", func(request *Request) *Response {
+		return Forbidden()
+	})
+
+	http.Get("/users/1")
+	http.Get("/forbidden")
+	http.Get("/missing")
+
+	assert.Equal(t, 3, len(logger.messages))
+	assert.Regexp(t,
+		`^\[info\] method=GET route=/users/:id status=200 latency=\S+ bytes=\d+ `+
+			`ip=\S* request_id=[0-9a-f]{32} successful=true$`,
+		logger.messages[0])
+	assert.Regexp(t, `^\[warn\] method=GET route=/forbidden status=403 .* successful=false$`,
+		logger.messages[1])
+	assert.Regexp(t, `^\[warn\] method=GET route=/missing status=404 `, logger.messages[2])
+}
+
+// Configures access log levels and skipped routes.
+func TestAccessLogConfig(t *testing.T) {
+	api, handlers, http := newAPITest()
+	logger := &testLogger{}
+	api.SetLogger(logger)
+	api.SetAccessLog(AccessLogConfig{
+		StatusLevels: map[int]LogLevel{2: DebugLevel},
+		SkipRoutes:   []string{"/health"},
+	})
+	api.Map("get", "/health", handlers.emptyHandler)
+	api.Map("get", "/users", handlers.emptyHandler)
+
+	http.Get("/health")
+	http.Get("/users")
+
+	assert.Equal(t, 1, len(logger.messages))
+	assert.Regexp(t, `^\[debug\] method=GET route=/users status=200`, logger.messages[0])
+}
@@ -5,9 +5,12 @@
 package jo
 
 import (
+	"context"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
+	"runtime/debug"
 	"strings"
 	"time"
 
@@ -23,15 +26,43 @@ type API struct {
 	initRequestHandler RouteHandler
 	endRequestHandler  RouteHandler
 	gracefulTimeout    time.Duration
+
+	notFoundHandler         RouteHandler
+	methodNotAllowedHandler RouteHandler
+	productionMode          bool
+	errorMappings           []errorMapping
+	renderer                EnvelopeRenderer
+	accessLog               AccessLogConfig
+	requestIDHeader         string
+	timeout                 time.Duration
+	cors                    *CORSOptions
+	tlsOptions              TLSOptions
 }
 
 // Defaults.
 const defGracefulTimeout time.Duration = 60
 
+// defRequestIDHeader is a default header request ID is read from and written to.
+const defRequestIDHeader = "X-Request-ID"
+
+// requestContextKey is a key of Request stored in gin context.
+const requestContextKey = "jo.request"
+
+func defNotFoundHandler(request *Request) *Response {
+	return NotFound()
+}
+
+func defMethodNotAllowedHandler(request *Request) *Response {
+	return MethodNotAllowed()
+}
+
 // NewAPI creates new instance of API structure.
 func NewAPI() *API {
 	api := &API{}
 	api.SetGracefulTimeout(defGracefulTimeout)
+	api.SetNotFoundHandler(defNotFoundHandler)
+	api.SetMethodNotAllowedHandler(defMethodNotAllowedHandler)
+	api.SetRequestIDHeader(defRequestIDHeader)
 	return api
 }
 
@@ -56,11 +87,46 @@ func (api *API) SetEndRequestHandler(handler RouteHandler) {
 	api.endRequestHandler = handler
 }
 
+// SetNotFoundHandler sets a handler function which will be called on requests
+// to unmapped paths. Default handler returns NotFound response.
+// Init request handler isn't called on such requests, end request handler is.
+func (api *API) SetNotFoundHandler(handler RouteHandler) {
+	api.notFoundHandler = handler
+}
+
+// SetMethodNotAllowedHandler sets a handler function which will be called on requests
+// to mapped paths via HTTP method which isn't mapped.
+// Default handler returns MethodNotAllowed response. Allow header is set
+// with a list of methods mapped on the path before the handler is called.
+// Init request handler isn't called on such requests, end request handler is.
+func (api *API) SetMethodNotAllowedHandler(handler RouteHandler) {
+	api.methodNotAllowedHandler = handler
+}
+
 // SetLogger sets user defined logger to be made available for every request handler.
+// If logger implements StructuredLogger, every request gets its own logger
+// with route, method and request ID fields, and access log is written as fields.
 func (api *API) SetLogger(logger ILogger) {
 	api.logger = logger
 }
 
+// SetProductionMode enables or disables production mode.
+// In production mode internal error details, such as panic messages
+// or messages of errors passed to Error, are hidden from responses.
+func (api *API) SetProductionMode(enabled bool) {
+	api.productionMode = enabled
+}
+
+// SetTimeout sets maximum duration of request handling. When handlers don't finish
+// in time, 504 Gateway Timeout response is returned. Handlers aren't interrupted:
+// they keep running after 504 is sent and their output is discarded, so they
+// should stop their work as soon as Request.Ctx is done.
+// Timeout can be overridden on specific routes via WithTimeout option.
+// Zero value, which is default, means no timeout.
+func (api *API) SetTimeout(timeout time.Duration) {
+	api.timeout = timeout
+}
+
 // SetGracefulTimeout sets timeout in seconds to wait
 // for connections to finish before app restart. Default value is 1 min.
 func (api *API) SetGracefulTimeout(timeoutSeconds time.Duration) {
@@ -70,19 +136,46 @@ func (api *API) SetGracefulTimeout(timeoutSeconds time.Duration) {
 // Map assigns a chain of handlers to a specific URL path
 // available via specific HTTP methods.
 // List of HTTP methods should be specified as a string e.g. "get,post,put" or just "get".
+// Supported methods are get, post, put, patch, delete, head and options.
+// Keyword "any" maps handlers on every supported method.
+// Map panics if unsupported HTTP method is specified.
+// GET routes also respond to HEAD requests unless HEAD is mapped explicitly.
 // Path format is compatible with gin https://github.com/gin-gonic/gin#parameters-in-path
 // Handlers must be specified in consequent order. They are called in that same order
 // and response might be returned on every handler
 // if it returns response with EndRequest flag.
-func (api *API) Map(httpMethods string, path string, handlers ...RouteHandler) {
-	httpMethodsSplit := strings.Split(httpMethods, ",")
+// Returns created routes, route options such as WithEnvelope are applied to them
+// via RouteList.With.
+func (api *API) Map(httpMethods string, path string, handlers ...RouteHandler) RouteList {
+	return api.addRoutes(httpMethods, path, nil, handlers)
+}
+
+// Routes returns a list of routes mapped on API.
+func (api *API) Routes() []*Route {
+	return api.routes
+}
+
+// addRoutes creates a route for every HTTP method specified and stores it in API.
+// Group is nil for routes mapped directly on API. Group handlers are prepended
+// to the route handlers. Returns created routes.
+func (api *API) addRoutes(
+	httpMethods string, path string, group *Group, handlers []RouteHandler) RouteList {
+	httpMethodsSplit := parseHTTPMethods(httpMethods, path)
+	template := &Route{}
+	template.Path = path
+	template.group = group
+	if group != nil {
+		template.Handlers = append(template.Handlers, group.handlers...)
+	}
+	template.Handlers = append(template.Handlers, handlers...)
+	routes := make(RouteList, 0, len(httpMethodsSplit))
 	for _, httpMethod := range httpMethodsSplit {
-		route := &Route{}
-		route.Method = strings.ToLower(strings.TrimSpace(httpMethod))
-		route.Path = path
-		route.Handlers = handlers
-		api.routes = append(api.routes, route)
+		route := *template
+		route.Method = httpMethod
+		api.routes = append(api.routes, &route)
+		routes = append(routes, &route)
 	}
+	return routes
 }
 
 // Run starts API on specified TCP address.
@@ -94,9 +187,14 @@ func (api *API) Run(addr string) error {
 
 // RunTLS starts API on specified TCP address, serving requests via TLS.
 // Certificate and key file paths must be specified.
+// TLS can be configured, e.g. to require client certificates, via SetTLSOptions.
 func (api *API) RunTLS(addr string, certFile string, keyFile string) error {
+	tlsConfig, err := api.createTLSConfig()
+	if err != nil {
+		return err
+	}
 	engine := api.buildEngine()
-	httpServer := &http.Server{Handler: engine, Addr: addr}
+	httpServer := &http.Server{Handler: engine, Addr: addr, TLSConfig: tlsConfig}
 	return graceful.ListenAndServeTLS(httpServer, certFile, keyFile, api.gracefulTimeout)
 }
 
@@ -119,91 +217,384 @@ func (api *API) createUnixSocketListener(file string) (net.Listener, error) {
 
 // buildEngine creates instance of a gin engine and adds routes to it.
 func (api *API) buildEngine() *gin.Engine {
-	engine := gin.Default()
+	engine := gin.New()
+	engine.Use(api.logAccess, gin.Recovery())
+	engine.HandleMethodNotAllowed = true
+	engine.NoRoute(api.createFallbackHandlerWrapper(api.notFoundHandler))
+	engine.NoMethod(api.allowMethods, api.createFallbackHandlerWrapper(api.methodNotAllowedHandler))
 	api.buildRoutes(engine)
 	return engine
 }
 
 // buildRoutes goes through a list of defined routes and builds them into gin engine.
+// GET routes are also mapped on HEAD method unless there's explicit HEAD route
+// on the same path. When CORS is enabled preflight handlers are mapped too.
 func (api *API) buildRoutes(engine *gin.Engine) {
+	headPaths := make(map[string]bool)
+	for _, route := range api.routes {
+		if route.Method == "head" {
+			headPaths[route.Path] = true
+		}
+	}
 	for _, route := range api.routes {
 		api.mapRoute(route, engine)
+		if route.Method == "get" && !headPaths[route.Path] {
+			headRoute := *route
+			headRoute.Method = "head"
+			api.mapRoute(&headRoute, engine)
+			headPaths[route.Path] = true
+		}
 	}
+	api.buildPreflightRoutes(engine)
 }
 
 // mapRoute creates gin-specific handler wrapped around specified handlers and maps in
 // on route.
 func (api *API) mapRoute(route *Route, engine *gin.Engine) {
-	handlerWrapper := api.createHandlerWrapper(route.Handlers)
+	handlerWrapper := api.createHandlerWrapper(route)
 	mapRouteHandler(route, handlerWrapper, engine)
 }
 
 // createHandlerWrapper creates gin-specific handler wrapper.
-func (api *API) createHandlerWrapper(handlers []RouteHandler) gin.HandlerFunc {
+func (api *API) createHandlerWrapper(route *Route) gin.HandlerFunc {
+	handlers := route.authorizedHandlers()
 	return func(innerContext *gin.Context) {
-		request, response := api.initRequest(innerContext)
+		request := api.createRequestContext(innerContext, route, Next(nil))
+		timeout := api.timeout
+		if route.Timeout > 0 {
+			timeout = route.Timeout
+		}
+		response := api.callHandlersWithTimeout(request, handlers, timeout)
 		if response.EndRequest {
 			api.endRequest(innerContext, request, response)
-			return
 		}
+	}
+}
+
+// callHandlersWithTimeout calls handlers waiting for them no longer than timeout.
+// If timeout isn't positive handlers are called synchronously.
+// Otherwise they're called in separate goroutine with a copy of request, which
+// output is buffered. When handlers finish in time, state of the copy is taken
+// over by request and buffered output is written. When request context is done
+// before they finish, response is created from context error while handlers
+// keep running in background and their output is discarded.
+func (api *API) callHandlersWithTimeout(
+	request *Request, handlers []RouteHandler, timeout time.Duration) *Response {
+	if timeout <= 0 {
+		return api.callHandlers(request, handlers)
+	}
+	ctx, cancel := context.WithTimeout(request.ctx, timeout)
+	defer cancel()
+	request.ctx = ctx
+
+	handlerRequest := request.clone()
+	writer := newTimeoutWriter(ctx, request.Context.Writer)
+	handlerRequest.Context = request.Context.Copy()
+	handlerRequest.Context.Writer = writer
+	handlerRequest.Context.Request = request.Context.Request.WithContext(ctx)
+	done := make(chan *Response, 1)
+	go func() {
+		done <- api.callHandlers(handlerRequest, handlers)
+	}()
+	select {
+	case response := <-done:
+		request.takeOver(handlerRequest)
+		writer.writeTo(request.Context.Writer)
+		return response
+	case <-ctx.Done():
+		return Error(ctx.Err())
+	}
+}
+
+// callHandlers calls init request handlers and then route handlers one by one
+// until one of them ends request. Panics are recovered into error response.
+func (api *API) callHandlers(request *Request, handlers []RouteHandler) (response *Response) {
+	defer api.recoverPanic(request, &response)
+	response = api.initRequest(request)
+	if response.EndRequest {
+		return response
+	}
+
+	for _, handler := range handlers {
+		response = api.callHandler(handler, request)
+		if response.EndRequest {
+			return response
+		}
+		request.PrevHandlerResponse = response
+	}
+	return response
+}
+
+// recoverPanic recovers from panic in request handlers, logs it with stack trace
+// and replaces response with internal error. Must be called via defer.
+func (api *API) recoverPanic(request *Request, response **Response) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	*response = api.createPanicResponse(request, recovered)
+}
+
+// recoverRenderPanic recovers from panic in renderer and logs it with stack trace.
+// Internal error is rendered by StandardRenderer unless something was written
+// already. Must be called via defer.
+func (api *API) recoverRenderPanic(request *Request) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	response := api.createPanicResponse(request, recovered)
+	if !request.Context.Writer.Written() {
+		StandardRenderer{}.Render(request, response)
+	}
+}
+
+// createPanicResponse logs recovered panic and creates internal error response.
+// Panic message is hidden in production mode.
+func (api *API) createPanicResponse(request *Request, recovered interface{}) *Response {
+	if structuredLogger, ok := request.Logger.(StructuredLogger); ok {
+		structuredLogger.Log(ErrorLevel, "Panic recovered",
+			"panic", fmt.Sprintf("%v", recovered), "stack", string(debug.Stack()))
+	} else if api.logger != nil {
+		api.logger.Error("Panic recovered: %v\n%s", recovered, debug.Stack())
+	}
+	message := fmt.Sprintf("%v", recovered)
+	if api.productionMode {
+		message = "Internal Error"
+	}
+	response := ErrorMessage(message)
+	response.Error.RequestID = request.ID()
+	return response
+}
 
-		for _, handler := range handlers {
-			response = handler(request)
-			if response.EndRequest {
-				api.endRequest(innerContext, request, response)
-				return
+// createFallbackHandlerWrapper creates gin-specific handler wrapper for requests
+// which don't match any route. Only end request handler is called around the handler.
+func (api *API) createFallbackHandlerWrapper(handler RouteHandler) gin.HandlerFunc {
+	return func(innerContext *gin.Context) {
+		request := api.createRequestContext(innerContext, nil, Next(nil))
+		response := api.callFallbackHandler(request, handler)
+		api.endRequest(innerContext, request, response)
+	}
+}
+
+func (api *API) callFallbackHandler(request *Request, handler RouteHandler) (response *Response) {
+	defer api.recoverPanic(request, &response)
+	return api.callHandler(handler, request)
+}
+
+// allowMethods sets Allow header with a list of HTTP methods mapped on requested path.
+func (api *API) allowMethods(innerContext *gin.Context) {
+	var allowed []string
+	for _, method := range api.findPathMethods(innerContext.Request.URL.Path) {
+		allowed = append(allowed, strings.ToUpper(method))
+	}
+	innerContext.Header("Allow", strings.Join(allowed, ", "))
+}
+
+// findPathMethods returns a list of HTTP methods mapped on routes matching specified path.
+// HEAD is included for GET routes because they respond to HEAD requests too.
+func (api *API) findPathMethods(path string) []string {
+	found := make(map[string]bool)
+	for _, route := range api.routes {
+		if matchRoutePath(route.Path, path) {
+			found[route.Method] = true
+			if route.Method == "get" {
+				found["head"] = true
 			}
-			request.PrevHandlerResponse = response
 		}
 	}
+	var methods []string
+	for _, method := range supportedHTTPMethods {
+		if found[method] {
+			methods = append(methods, method)
+		}
+	}
+	return methods
 }
 
 // initRequest is called at the beginning of every handled request.
-// initRequestHandler is called if specified.
-func (api *API) initRequest(innerContext *gin.Context) (*Request, *Response) {
-	response := Next(nil)
-	request := api.createRequestContext(innerContext, response)
-	if api.initRequestHandler != nil {
-		response = api.initRequestHandler(request)
+// initRequestHandler is called if specified, then init request handlers
+// of route groups are called from the outermost group.
+func (api *API) initRequest(request *Request) *Response {
+	response := request.PrevHandlerResponse
+	initHandlers := []RouteHandler{api.initRequestHandler}
+	for _, group := range request.route.group.lineage() {
+		initHandlers = append(initHandlers, group.initRequestHandler)
+	}
+	for _, handler := range initHandlers {
+		if handler == nil {
+			continue
+		}
+		response = api.callHandler(handler, request)
 		request.PrevHandlerResponse = response
+		if response.EndRequest {
+			break
+		}
 	}
-	return request, response
+	return response
 }
 
 // endRequest is called at the end of every handled request.
-// endRequestHandler is called if specified.
+// End request handlers of route groups are called from the innermost group,
+// then endRequestHandler is called if specified. Panics in end request handlers
+// and in renderer are recovered into error response.
 func (api *API) endRequest(
 	innerContext *gin.Context,
 	request *Request,
 	response *Response) {
-	if api.endRequestHandler != nil {
-		request.PrevHandlerResponse = response
-		response = api.endRequestHandler(request)
-	}
-	// Every request eventually returns JSON no matter of its status.
-	// NOTE the object itself is not returned because we should hide error field
-	// on successful responses.
-	jsonResponse := make(map[string]interface{})
-	jsonResponse["successful"] = response.Successful
-	jsonResponse["data"] = response.Data
-	if !response.Successful {
-		jsonResponse["error"] = response.Error
-	}
-	innerContext.JSON(response.HTTPCode, jsonResponse)
+	response = api.callEndHandlers(request, response)
+	request.response = response
+	api.renderResponse(request, response)
 	innerContext.Abort()
 }
 
+// callEndHandlers resolves response error and calls end request handlers.
+func (api *API) callEndHandlers(request *Request, response *Response) (result *Response) {
+	defer api.recoverPanic(request, &result)
+	result = withRequestID(api.resolveError(response), request)
+	var endHandlers []RouteHandler
+	if request.route != nil {
+		groups := request.route.group.lineage()
+		for i := len(groups) - 1; i >= 0; i-- {
+			endHandlers = append(endHandlers, groups[i].endRequestHandler)
+		}
+	}
+	endHandlers = append(endHandlers, api.endRequestHandler)
+	for _, handler := range endHandlers {
+		if handler == nil {
+			continue
+		}
+		request.PrevHandlerResponse = result
+		result = api.callHandler(handler, request)
+	}
+	return withRequestID(result, request)
+}
+
+// withRequestID returns copy of failed response with ID of the request set.
+// Response itself isn't changed, since handlers may return the same response
+// to many requests.
+func withRequestID(response *Response, request *Request) *Response {
+	if response.Successful || response.Error.RequestID == request.ID() {
+		return response
+	}
+	identified := *response
+	identified.Error.RequestID = request.ID()
+	return &identified
+}
+
+// renderResponse renders response recovering from panics in renderer.
+func (api *API) renderResponse(request *Request, response *Response) {
+	defer api.recoverRenderPanic(request)
+	api.render(request, response)
+}
+
+// callHandler calls handler and merges headers and cookies of previous
+// response into the one returned by handler. Error of the response is resolved
+// via registered errors, so next handlers see the same response as client.
+func (api *API) callHandler(handler RouteHandler, request *Request) *Response {
+	response := mergeResponseHeaders(handler(request), request.PrevHandlerResponse)
+	return api.resolveError(response)
+}
+
 // createRequestContext creates context passed to request handlers.
+// Route is nil for requests which don't match any route.
 func (api *API) createRequestContext(
-	innerContext *gin.Context, prevResponse *Response) *Request {
+	innerContext *gin.Context, route *Route, prevResponse *Response) *Request {
 	context := &Request{}
 	context.Context = innerContext
 	context.GlobalContext = api.globalContext
 	context.PrevHandlerResponse = prevResponse
-	context.Logger = api.logger
+	context.route = route
+	context.ctx = innerContext.Request.Context()
+	context.id = api.createRequestID(innerContext)
+	innerContext.Header(api.requestIDHeader, context.id)
+	context.Logger = api.createRequestLogger(context)
+	innerContext.Set(requestContextKey, context)
 	return context
 }
 
+// createRequestLogger returns API logger. If it's StructuredLogger, returned logger
+// has route, method and request ID fields.
+func (api *API) createRequestLogger(request *Request) ILogger {
+	structuredLogger, ok := api.logger.(StructuredLogger)
+	if !ok {
+		return api.logger
+	}
+	routePath := request.Context.Request.URL.Path
+	if request.route != nil {
+		routePath = request.route.Path
+	}
+	return structuredLogger.With(
+		"route", routePath,
+		"method", request.Context.Request.Method,
+		"request_id", request.ID())
+}
+
+// matchRoutePath checks whether URL path matches gin route path pattern
+// with :name and *name parameters.
+func matchRoutePath(pattern string, path string) bool {
+	patternSegments := strings.Split(pattern, "/")
+	pathSegments := strings.Split(path, "/")
+	for i, patternSegment := range patternSegments {
+		if strings.HasPrefix(patternSegment, "*") {
+			return true
+		}
+		if i >= len(pathSegments) {
+			return false
+		}
+		if strings.HasPrefix(patternSegment, ":") {
+			if len(pathSegments[i]) == 0 {
+				return false
+			}
+			continue
+		}
+		if patternSegment != pathSegments[i] {
+			return false
+		}
+	}
+	return len(patternSegments) == len(pathSegments)
+}
+
+// supportedHTTPMethods is a list of HTTP methods handlers can be mapped on.
+var supportedHTTPMethods = []string{"get", "post", "put", "patch", "delete", "head", "options"}
+
+// parseHTTPMethods splits comma separated list of HTTP methods
+// and expands "any" keyword. Duplicate methods are mapped once.
+// Panics on unsupported method.
+func parseHTTPMethods(httpMethods string, path string) []string {
+	var methods []string
+	added := make(map[string]bool)
+	add := func(method string) {
+		if !added[method] {
+			added[method] = true
+			methods = append(methods, method)
+		}
+	}
+	for _, httpMethod := range strings.Split(httpMethods, ",") {
+		method := strings.ToLower(strings.TrimSpace(httpMethod))
+		if method == "any" {
+			for _, supported := range supportedHTTPMethods {
+				add(supported)
+			}
+			continue
+		}
+		if !isSupportedHTTPMethod(method) {
+			panic(fmt.Sprintf("jo: unsupported HTTP method %q mapped on %s", httpMethod, path))
+		}
+		add(method)
+	}
+	return methods
+}
+
+func isSupportedHTTPMethod(method string) bool {
+	for _, supported := range supportedHTTPMethods {
+		if method == supported {
+			return true
+		}
+	}
+	return false
+}
+
 // mapRouteHandler maps gin handler on specific path and HTTP method.
 func mapRouteHandler(route *Route, handler gin.HandlerFunc, engine *gin.Engine) {
 	switch route.Method {
@@ -217,5 +608,9 @@ func mapRouteHandler(route *Route, handler gin.HandlerFunc, engine *gin.Engine)
 		engine.PATCH(route.Path, handler)
 	case "delete":
 		engine.DELETE(route.Path, handler)
+	case "head":
+		engine.HEAD(route.Path, handler)
+	case "options":
+		engine.OPTIONS(route.Path, handler)
 	}
 }
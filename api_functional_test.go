@@ -5,6 +5,9 @@
 package jo
 
 import (
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -155,7 +158,7 @@ func TestEachHTTPMethod(t *testing.T) {
 	api, handlers, http := newAPITest()
 	api.SetEndRequestHandler(handlers.patchResponse)
 	endpoint := "/a/b/c/d/e"
-	httpMethods := "get,post,put,patch,delete"
+	httpMethods := "get,post,put,patch,delete,head,options"
 	api.Map(httpMethods, endpoint, handlers.emptyHandler)
 
 	httpMethodsSplit := strings.Split(httpMethods, ",")
@@ -174,8 +177,192 @@ func TestEachHTTPMethod(t *testing.T) {
 			response = http.Patch(endpoint, requestBody)
 		case "delete":
 			response = http.Delete(endpoint)
+		case "head":
+			// HEAD responses have no body, so only HTTP code can be checked.
+			assert.Equal(t, 200, http.Head(endpoint).HTTPCode)
+			continue
+		case "options":
+			response = http.Options(endpoint)
 		}
 
 		AssertOk(t, response)
 	}
 }
+
+// Creates API with mapping on any HTTP method and checks a few of them.
+func TestAnyHTTPMethod(t *testing.T) {
+	api, handlers, http := newAPITest()
+	endpoint := "/any"
+	api.Map("any", endpoint, handlers.emptyHandler)
+
+	AssertOk(t, http.Get(endpoint))
+	AssertOk(t, http.Post(endpoint, nil))
+	AssertOk(t, http.Options(endpoint))
+	assert.Equal(t, 200, http.Head(endpoint).HTTPCode)
+}
+
+// Maps handlers on duplicate HTTP methods and checks every method is mapped once.
+func TestDuplicateHTTPMethods(t *testing.T) {
+	api, handlers, http := newAPITest()
+	api.Map("get,any,GET", "/dup", handlers.emptyHandler)
+
+	assert.Len(t, api.Routes(), len(supportedHTTPMethods))
+	AssertOk(t, http.Get("/dup"))
+	AssertOk(t, http.Post("/dup", nil))
+}
+
+// Creates API with GET route and sends HEAD request to it.
+// Response must have headers of GET response but no body.
+func TestAutomaticHead(t *testing.T) {
+	api, handlers, _ := newAPITest()
+	endpoint := "/head"
+	api.Map("get", endpoint, handlers.emptyMessageHandler)
+	engine := api.buildEngine()
+
+	getRecorder := httptest.NewRecorder()
+	engine.ServeHTTP(getRecorder, createHTTPTestRequest("GET", endpoint, nil))
+	headRecorder := httptest.NewRecorder()
+	engine.ServeHTTP(headRecorder, createHTTPTestRequest("HEAD", endpoint, nil))
+
+	assert.Equal(t, 200, headRecorder.Code)
+	assert.Empty(t, headRecorder.Body.String())
+	assert.Equal(t,
+		strconv.Itoa(getRecorder.Body.Len()), headRecorder.Header().Get("Content-Length"))
+	assert.Equal(t,
+		getRecorder.Header().Get("Content-Type"), headRecorder.Header().Get("Content-Type"))
+}
+
+// Creates API with explicit HEAD route which must take precedence over automatic one.
+func TestExplicitHead(t *testing.T) {
+	api, handlers, http := newAPITest()
+	endpoint := "/head"
+	api.Map("get", endpoint, handlers.emptyHandler)
+	api.Map("head", endpoint, func(request *Request) *Response {
+		return Forbidden()
+	})
+
+	assert.Equal(t, 403, http.Head(endpoint).HTTPCode)
+}
+
+// Sends request to unmapped path and expects enveloped 404 response.
+func TestNotFound(t *testing.T) {
+	api, handlers, http := newAPITest()
+	api.Map("get", "/exists", handlers.emptyHandler)
+
+	response := http.Get("/does/not/exist")
+
+	AssertNotFound(t, response)
+}
+
+// Sends request to mapped path via unmapped method and expects enveloped 405 response
+// with Allow header listing mapped methods.
+func TestMethodNotAllowed(t *testing.T) {
+	api, handlers, http := newAPITest()
+	api.Map("get,put", "/users/:id", handlers.emptyHandler)
+
+	response := http.Post("/users/1", nil)
+	AssertMethodNotAllowed(t, response)
+
+	recorder := httptest.NewRecorder()
+	api.buildEngine().ServeHTTP(recorder, createHTTPTestRequest("DELETE", "/users/1", nil))
+	assert.Equal(t, 405, recorder.Code)
+	assert.Equal(t, "GET, PUT, HEAD", recorder.Header().Get("Allow"))
+}
+
+// Sets custom not found and method not allowed handlers.
+// End request handler must be called for both of them.
+func TestCustomFallbackHandlers(t *testing.T) {
+	api, handlers, http := newAPITest()
+	api.SetEndRequestHandler(handlers.patchResponse)
+	api.SetNotFoundHandler(func(request *Request) *Response {
+		return NotFoundMessage("No such thing")
+	})
+	api.SetMethodNotAllowedHandler(func(request *Request) *Response {
+		return MethodNotAllowedMessage("Try another method")
+	})
+	api.Map("get", "/exists", handlers.emptyHandler)
+
+	response := http.Get("/missing")
+	AssertNotFound(t, response, "No such thing")
+	assert.NotNil(t, response.Data.(map[string]interface{})["date"])
+
+	response = http.Delete("/exists")
+	AssertMethodNotAllowed(t, response, "Try another method")
+}
+
+// Creates API with panicking handler. Panic must be recovered into error response,
+// logged and end request handler must still be called.
+func TestPanicRecovery(t *testing.T) {
+	api, handlers, http := newAPITest()
+	logger := &testLogger{}
+	api.SetLogger(logger)
+	api.SetAccessLog(AccessLogConfig{Disabled: true})
+	api.SetEndRequestHandler(handlers.patchResponse)
+	api.Map("get", "/panic", func(request *Request) *Response {
+		panic("something went wrong")
+	})
+
+	response := http.Get("/panic")
+
+	AssertResponseError(t, response, "something went wrong")
+	assert.NotNil(t, response.Data.(map[string]interface{})["date"])
+	assert.Equal(t, 1, len(logger.messages))
+	assert.Contains(t, logger.messages[0], "something went wrong")
+}
+
+// Creates API in production mode with panicking init request handler.
+// Panic details must be hidden from response.
+func TestPanicRecoveryProductionMode(t *testing.T) {
+	api, handlers, http := newAPITest()
+	api.SetProductionMode(true)
+	api.SetInitRequestHandler(func(request *Request) *Response {
+		panic("secret details")
+	})
+	api.Map("get", "/panic", handlers.emptyHandler)
+
+	response := http.Get("/panic")
+
+	AssertResponseError(t, response, "Internal Error")
+}
+
+// Creates API with panicking end request handler. Panic must be recovered into error response.
+func TestPanicRecoveryEndRequestHandler(t *testing.T) {
+	api, handlers, http := newAPITest()
+	api.SetEndRequestHandler(func(request *Request) *Response {
+		panic("end handler failed")
+	})
+	api.Map("get", "/panic", handlers.emptyHandler)
+
+	response := http.Get("/panic")
+
+	AssertResponseError(t, response, "end handler failed")
+}
+
+// Creates API with panicking renderer and with unserializable response data.
+// Both must be rendered as internal error in standard envelope.
+func TestPanicRecoveryRender(t *testing.T) {
+	api, _, _ := newAPITest()
+	api.SetAccessLog(AccessLogConfig{Disabled: true})
+	api.Map("get", "/func", func(request *Request) *Response {
+		return Ok(func() {})
+	})
+	api.Map("get", "/renderer", func(request *Request) *Response {
+		return Ok(nil)
+	}).With(WithRenderer(panickingRenderer{}))
+
+	for _, url := range []string{"/func", "/renderer"} {
+		recorder := recordRequest(api, "GET", url, nil, nil)
+		assert.Equal(t, 500, recorder.Code)
+		assert.Contains(t, recorder.Header().Get("Content-Type"), "application/json")
+		body := make(map[string]interface{})
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		assert.Equal(t, false, body["successful"])
+		assert.NotEmpty(t, body["error"].(map[string]interface{})["request_id"])
+	}
+}
+
+type panickingRenderer struct{}
+
+func (panickingRenderer) Render(request *Request, response *Response) {
+	panic("renderer failed")
+}
@@ -5,7 +5,9 @@
 package jo
 
 import (
+	"crypto/tls"
 	"fmt"
+	"net/http"
 	"runtime"
 	"testing"
 	"time"
@@ -15,6 +17,7 @@ import (
 
 const inTestHost = "localhost:8989"
 const inTestHostTLS = "localhost:9898"
+const inTestHostMutualTLS = "localhost:9897"
 const inTestSocket = "/tmp/jo_test_socket.sock"
 const inTestCRTFile = "test_files/certificate.crt"
 const inTestKeyFile = "test_files/privateKey.key"
@@ -71,6 +74,45 @@ func TestRunTLS(t *testing.T) {
 	inTestDefaultRouteTLS(t, host)
 }
 
+// Runs API on TCP port via TLS requiring client certificates.
+func TestRunMutualTLS(t *testing.T) {
+	api := NewAPI()
+	api.SetTLSOptions(TLSOptions{
+		ClientCertMode: RequireClientCert,
+		ClientCAFile:   inTestCRTFile,
+		// Test certificate has expired, so it's verified at the time it was valid.
+		Config: &tls.Config{Time: func() time.Time {
+			return time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+		}},
+	})
+	api.Map("get", "/", func(request *Request) *Response {
+		return Ok(request.PeerCertificate())
+	})
+	host := inTestHostMutualTLS
+	go func() {
+		assert.NoError(t,
+			api.RunTLS(host, inTestCRTFile, inTestKeyFile))
+	}()
+	waitServer()
+
+	https := NewHTTPIntegrationTestTLS(host, inTestCRTFile, inTestKeyFile)
+	response := https.Get("/")
+	AssertOk(t, response)
+	peer := response.Data.(map[string]interface{})
+	assert.Equal(t, "Slavik", peer["common_name"])
+	assert.Contains(t, peer["subject"], "O=Slavik")
+	assert.Len(t, peer["fingerprint"], 64)
+
+	withoutCertificate := NewHTTPIntegrationTest(host)
+	withoutCertificate.proto = "https"
+	withoutCertificate.transport = &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	assert.Panics(t, func() {
+		withoutCertificate.Get("/")
+	})
+}
+
 func waitServer() {
 	time.Sleep(10 * time.Millisecond)
 }
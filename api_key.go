@@ -0,0 +1,233 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// APIKey is a stored API key. Only hash of the key is stored.
+type APIKey struct {
+	// ID identifies the key, e.g. in logs and on rotation. It isn't secret.
+	ID string `json:"id"`
+
+	// Hash is a hex-encoded SHA-256 hash of the key, see HashAPIKey.
+	Hash string `json:"hash"`
+
+	// Owner is a client the key was issued to. It becomes principal subject.
+	Owner string `json:"owner"`
+
+	// Scopes are permissions granted to the key.
+	Scopes []string `json:"scopes"`
+
+	// ExpiresAt is a time the key stops working. Zero value means never.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// KeyStore is a definition of an object which looks API keys up.
+type KeyStore interface {
+	// FindKey returns key with specified hash or nil if there's no such key.
+	FindKey(hash string) (*APIKey, error)
+}
+
+// APIKeyConfig configures API key authentication handler.
+type APIKeyConfig struct {
+	// Store looks keys up. It's required.
+	Store KeyStore
+
+	// Header is a request header key is read from. Default is X-API-Key.
+	Header string
+
+	// QueryParam is a query parameter key is read from when there's no header.
+	// Keys aren't read from query when it's empty.
+	QueryParam string
+}
+
+// defAPIKeyHeader is a default header API key is read from.
+const defAPIKeyHeader = "X-API-Key"
+
+// APIKeyAuth creates a handler which authenticates requests with API keys.
+// On success key owner and scopes are stored on request as Principal
+// and key ID is stored as "key_id" claim. Requests without valid key get
+// 401 Unauthorized response. APIKeyAuth panics if there's no store.
+func APIKeyAuth(config APIKeyConfig) RouteHandler {
+	if config.Store == nil {
+		panic("jo: API key store isn't specified")
+	}
+	if len(config.Header) == 0 {
+		config.Header = defAPIKeyHeader
+	}
+	return func(request *Request) *Response {
+		rawKey := request.Context.Request.Header.Get(config.Header)
+		if len(rawKey) == 0 && len(config.QueryParam) > 0 {
+			rawKey = request.Context.Query(config.QueryParam)
+		}
+		if len(rawKey) == 0 {
+			return unauthorized(ErrCodeMissingCredentials, "Missing API key", "")
+		}
+		hash := HashAPIKey(rawKey)
+		key, err := config.Store.FindKey(hash)
+		if err != nil {
+			return Error(err)
+		}
+		if key == nil {
+			return unauthorized(ErrCodeInvalidCredentials, "Invalid API key", "")
+		}
+		if !key.ExpiresAt.IsZero() && !time.Now().Before(key.ExpiresAt) {
+			return unauthorized(ErrCodeExpiredCredentials, "API key is expired", "")
+		}
+		request.SetPrincipal(&Principal{
+			Subject: key.Owner,
+			Scopes:  key.Scopes,
+			Claims:  map[string]interface{}{"key_id": key.ID},
+		})
+		return Next(nil)
+	}
+}
+
+// HashAPIKey returns hex-encoded SHA-256 hash of the key as it's kept in stores.
+func HashAPIKey(rawKey string) string {
+	hash := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(hash[:])
+}
+
+// GenerateAPIKey generates random 256-bit key encoded as URL-safe base64.
+func GenerateAPIKey() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// MemoryKeyStore keeps API keys in memory of the process.
+// Zero value is an empty store ready to use.
+type MemoryKeyStore struct {
+	mutex  sync.RWMutex
+	byHash map[string]*APIKey
+}
+
+// NewMemoryKeyStore creates store with specified keys.
+func NewMemoryKeyStore(keys ...APIKey) *MemoryKeyStore {
+	store := &MemoryKeyStore{byHash: make(map[string]*APIKey)}
+	store.Set(keys...)
+	return store
+}
+
+// FindKey returns key with specified hash or nil if there's no such key.
+func (store *MemoryKeyStore) FindKey(hash string) (*APIKey, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	return store.byHash[hash], nil
+}
+
+// Add adds key to the store. Key with the same hash is replaced.
+func (store *MemoryKeyStore) Add(key APIKey) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.init()
+	store.byHash[key.Hash] = &key
+}
+
+// Remove removes key with specified ID.
+func (store *MemoryKeyStore) Remove(id string) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.removeByID(id)
+}
+
+// Set replaces all keys of the store with specified ones.
+func (store *MemoryKeyStore) Set(keys ...APIKey) {
+	byHash := make(map[string]*APIKey, len(keys))
+	for i := range keys {
+		byHash[keys[i].Hash] = &keys[i]
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.byHash = byHash
+}
+
+// Rotate adds new key and makes key with specified ID expire after grace period,
+// so clients can switch to the new key without downtime. Old key is removed
+// right away if grace period isn't positive.
+func (store *MemoryKeyStore) Rotate(id string, newKey APIKey, grace time.Duration) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.init()
+	if grace <= 0 {
+		store.removeByID(id)
+	} else {
+		expiresAt := time.Now().Add(grace)
+		for hash, key := range store.byHash {
+			if key.ID == id && (key.ExpiresAt.IsZero() || key.ExpiresAt.After(expiresAt)) {
+				expiring := *key
+				expiring.ExpiresAt = expiresAt
+				store.byHash[hash] = &expiring
+			}
+		}
+	}
+	store.byHash[newKey.Hash] = &newKey
+}
+
+// init creates map of keys if it isn't created yet.
+func (store *MemoryKeyStore) init() {
+	if store.byHash == nil {
+		store.byHash = make(map[string]*APIKey)
+	}
+}
+
+func (store *MemoryKeyStore) removeByID(id string) {
+	for hash, key := range store.byHash {
+		if key.ID == id {
+			delete(store.byHash, hash)
+		}
+	}
+}
+
+// FileKeyStore keeps API keys loaded from JSON file of the form
+//
+//	{"keys": [{"id": "...", "hash": "...", "owner": "...", "scopes": [...], "expires_at": "..."}]}
+//
+// Keys are rotated by updating the file and calling Reload. Keys added
+// via MemoryKeyStore methods aren't written to the file.
+type FileKeyStore struct {
+	MemoryKeyStore
+	path string
+}
+
+// NewFileKeyStore creates store with keys loaded from specified file.
+func NewFileKeyStore(path string) (*FileKeyStore, error) {
+	store := &FileKeyStore{path: path}
+	store.byHash = make(map[string]*APIKey)
+	if err := store.Reload(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Reload replaces keys of the store with the ones from the file.
+// Keys are kept intact if the file can't be loaded.
+func (store *FileKeyStore) Reload() error {
+	jsonBytes, err := os.ReadFile(store.path)
+	if err != nil {
+		return err
+	}
+	var keyFile struct {
+		Keys []APIKey `json:"keys"`
+	}
+	if err := json.Unmarshal(jsonBytes, &keyFile); err != nil {
+		return fmt.Errorf("Invalid API key file %s: %v", store.path, err)
+	}
+	store.Set(keyFile.Keys...)
+	return nil
+}
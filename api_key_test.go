@@ -0,0 +1,121 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Sends GET request with API key header and returns response status.
+func requestWithAPIKey(api *API, url string, key string) int {
+	return recordRequest(api, "GET", url, nil, map[string]string{"X-API-Key": key}).Code
+}
+
+// Authenticates requests with API keys from header and query.
+func TestAPIKeyAuth(t *testing.T) {
+	store := NewMemoryKeyStore(APIKey{
+		ID: "billing-1", Hash: HashAPIKey("secret-key"), Owner: "billing", Scopes: []string{"orders:read"}})
+	api, _, http := newAPITest()
+	auth := APIKeyAuth(APIKeyConfig{Store: store, QueryParam: "api_key"})
+	api.Map("get", "/me", auth, func(request *Request) *Response {
+		principal := request.Principal()
+		assert.Equal(t, []string{"orders:read"}, principal.Scopes)
+		assert.Equal(t, "billing-1", principal.Claims["key_id"])
+		return Ok(principal.Subject)
+	})
+
+	assert.Equal(t, 200, requestWithAPIKey(api, "/me", "secret-key"))
+	response := http.Get("/me?api_key=secret-key")
+	AssertOk(t, response)
+	assert.Equal(t, "billing", response.Data)
+
+	response = http.Get("/me")
+	assert.Equal(t, 401, response.HTTPCode)
+	assert.Equal(t, ErrCodeMissingCredentials, response.Error.Code)
+	response = http.Get("/me?api_key=wrong-key")
+	assert.Equal(t, 401, response.HTTPCode)
+	assert.Equal(t, ErrCodeInvalidCredentials, response.Error.Code)
+}
+
+// Rotates key and checks old key works during grace period only.
+func TestAPIKeyRotation(t *testing.T) {
+	store := NewMemoryKeyStore(APIKey{ID: "key-1", Hash: HashAPIKey("old-key"), Owner: "billing"})
+	api, handlers, _ := newAPITest()
+	api.Map("get", "/me", APIKeyAuth(APIKeyConfig{Store: store}), handlers.emptyHandler)
+
+	newKey, err := GenerateAPIKey()
+	assert.NoError(t, err)
+	store.Rotate("key-1", APIKey{ID: "key-2", Hash: HashAPIKey(newKey), Owner: "billing"}, time.Hour)
+	assert.Equal(t, 200, requestWithAPIKey(api, "/me", "old-key"))
+	assert.Equal(t, 200, requestWithAPIKey(api, "/me", newKey))
+
+	store.Add(APIKey{ID: "key-3", Hash: HashAPIKey("expired-key"), ExpiresAt: time.Now().Add(-time.Second)})
+	recorder := recordRequest(api, "GET", "/me", nil, map[string]string{"X-API-Key": "expired-key"})
+	assert.Equal(t, 401, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "API key is expired")
+
+	store.Rotate("key-2", APIKey{ID: "key-4", Hash: HashAPIKey("newest-key")}, 0)
+	assert.Equal(t, 401, requestWithAPIKey(api, "/me", newKey))
+	assert.Equal(t, 200, requestWithAPIKey(api, "/me", "newest-key"))
+
+	store.Remove("key-4")
+	assert.Equal(t, 401, requestWithAPIKey(api, "/me", "newest-key"))
+}
+
+// Checks zero value of memory key store is ready to use.
+func TestMemoryKeyStoreZeroValue(t *testing.T) {
+	store := &MemoryKeyStore{}
+	store.Add(APIKey{ID: "key-1", Hash: HashAPIKey("first-key")})
+	key, err := store.FindKey(HashAPIKey("first-key"))
+	assert.NoError(t, err)
+	assert.Equal(t, "key-1", key.ID)
+
+	rotated := &MemoryKeyStore{}
+	rotated.Rotate("key-1", APIKey{ID: "key-2", Hash: HashAPIKey("second-key")}, time.Hour)
+	key, err = rotated.FindKey(HashAPIKey("second-key"))
+	assert.NoError(t, err)
+	assert.Equal(t, "key-2", key.ID)
+}
+
+// Checks handler can't be created without store.
+func TestAPIKeyAuthInvalidConfig(t *testing.T) {
+	assert.PanicsWithValue(t, "jo: API key store isn't specified", func() {
+		APIKeyAuth(APIKeyConfig{})
+	})
+}
+
+// Loads keys from file and reloads them after file is updated.
+func TestFileKeyStore(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "keys.json")
+	writeKeys := func(rawKey string) {
+		content := `{"keys": [{"id": "key-1", "hash": "` + HashAPIKey(rawKey) + `", "owner": "billing"}]}`
+		assert.NoError(t, os.WriteFile(keyFile, []byte(content), 0600))
+	}
+	writeKeys("first-key")
+	store, err := NewFileKeyStore(keyFile)
+	assert.NoError(t, err)
+	key, _ := store.FindKey(HashAPIKey("first-key"))
+	assert.Equal(t, "billing", key.Owner)
+
+	writeKeys("second-key")
+	assert.NoError(t, store.Reload())
+	key, _ = store.FindKey(HashAPIKey("first-key"))
+	assert.Nil(t, key)
+	key, _ = store.FindKey(HashAPIKey("second-key"))
+	assert.Equal(t, "key-1", key.ID)
+
+	assert.NoError(t, os.WriteFile(keyFile, []byte("{"), 0600))
+	assert.Error(t, store.Reload())
+	key, _ = store.FindKey(HashAPIKey("second-key"))
+	assert.NotNil(t, key)
+
+	_, err = NewFileKeyStore(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
@@ -90,6 +90,19 @@ func TestMap(t *testing.T) {
 	assert.Equal(t, 7, len(api.routes), "There must be 7 routes for each HTTP method + path")
 }
 
+// Makes sure Map panics on unsupported HTTP method and "any" expands to every method.
+func TestMapHTTPMethods(t *testing.T) {
+	api := NewAPI()
+	handlers := newTestHandlers()
+	assert.Panics(t, func() {
+		api.Map("get,gte", "/a", handlers.emptyHandler)
+	})
+	assert.Empty(t, api.routes)
+
+	api.Map("any", "/b", handlers.emptyHandler)
+	assert.Equal(t, len(supportedHTTPMethods), len(api.routes))
+}
+
 // Creates API, adds simple route mapping and builds gin engine, creating gin-specific
 // handler for the route mapped.
 // Makes sure the engine isn't nil.
@@ -100,3 +113,14 @@ func TestBuildEngine(t *testing.T) {
 	engine := api.buildEngine()
 	assert.NotNil(t, engine)
 }
+
+// Matches URL paths against route patterns with parameters.
+func TestMatchRoutePath(t *testing.T) {
+	assert.True(t, matchRoutePath("/a/b", "/a/b"))
+	assert.False(t, matchRoutePath("/a/b", "/a/c"))
+	assert.False(t, matchRoutePath("/a/b", "/a/b/c"))
+	assert.True(t, matchRoutePath("/users/:id", "/users/1"))
+	assert.False(t, matchRoutePath("/users/:id", "/users/"))
+	assert.True(t, matchRoutePath("/files/*path", "/files/a/b/c"))
+	assert.True(t, matchRoutePath("/files/*path", "/files/"))
+}
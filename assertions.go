@@ -12,8 +12,34 @@ import (
 
 // AssertOk checks expected properties of Ok response.
 func AssertOk(t *testing.T, response *Response) {
+	AssertSuccess(200, t, response)
+}
+
+// AssertCreated checks expected properties of Created response.
+// Location header is checked if specified.
+func AssertCreated(t *testing.T, response *Response, locations ...string) {
+	AssertSuccess(201, t, response)
+	if len(locations) > 0 {
+		assert.Equal(t, locations[0], response.Headers.Get("Location"))
+	}
+}
+
+// AssertAccepted checks expected properties of Accepted response.
+func AssertAccepted(t *testing.T, response *Response) {
+	AssertSuccess(202, t, response)
+}
+
+// AssertNoContent checks expected properties of NoContent response.
+// NOTE response received over HTTP has no body, so only HTTP code is checked.
+func AssertNoContent(t *testing.T, response *Response) {
 	assert.NotNil(t, response)
-	assert.Equal(t, 200, response.HTTPCode)
+	assert.Equal(t, 204, response.HTTPCode)
+}
+
+// AssertSuccess checks expected properties of successful response with specific HTTP code.
+func AssertSuccess(code int, t *testing.T, response *Response) {
+	assert.NotNil(t, response)
+	assert.Equal(t, code, response.HTTPCode)
 	assert.True(t, response.Successful)
 }
 
@@ -39,6 +65,51 @@ func AssertUnauthorized(t *testing.T, response *Response, messages ...string) {
 	AssertHTTPError(401, "Unauthorized", t, response, messages...)
 }
 
+// AssertNotFound checks expected properties of NotFound response.
+func AssertNotFound(t *testing.T, response *Response, messages ...string) {
+	AssertHTTPError(404, "Not Found", t, response, messages...)
+}
+
+// AssertMethodNotAllowed checks expected properties of MethodNotAllowed response.
+func AssertMethodNotAllowed(t *testing.T, response *Response, messages ...string) {
+	AssertHTTPError(405, "Method Not Allowed", t, response, messages...)
+}
+
+// AssertConflict checks expected properties of Conflict response.
+func AssertConflict(t *testing.T, response *Response, messages ...string) {
+	AssertHTTPError(409, "Conflict", t, response, messages...)
+}
+
+// AssertGone checks expected properties of Gone response.
+func AssertGone(t *testing.T, response *Response, messages ...string) {
+	AssertHTTPError(410, "Gone", t, response, messages...)
+}
+
+// AssertPreconditionFailed checks expected properties of PreconditionFailed response.
+func AssertPreconditionFailed(t *testing.T, response *Response, messages ...string) {
+	AssertHTTPError(412, "Precondition Failed", t, response, messages...)
+}
+
+// AssertUnprocessableEntity checks expected properties of UnprocessableEntity response.
+func AssertUnprocessableEntity(t *testing.T, response *Response, messages ...string) {
+	AssertHTTPError(422, "Unprocessable Entity", t, response, messages...)
+}
+
+// AssertTooManyRequests checks expected properties of TooManyRequests response.
+func AssertTooManyRequests(t *testing.T, response *Response, messages ...string) {
+	AssertHTTPError(429, "Too Many Requests", t, response, messages...)
+}
+
+// AssertServiceUnavailable checks expected properties of ServiceUnavailable response.
+func AssertServiceUnavailable(t *testing.T, response *Response, messages ...string) {
+	AssertHTTPError(503, "Service Unavailable", t, response, messages...)
+}
+
+// AssertGatewayTimeout checks expected properties of GatewayTimeout response.
+func AssertGatewayTimeout(t *testing.T, response *Response, messages ...string) {
+	AssertHTTPError(504, "Gateway Timeout", t, response, messages...)
+}
+
 // AssertResponseError checks expected properties of Error response.
 func AssertResponseError(t *testing.T, response *Response, messages ...string) {
 	AssertHTTPError(500, "Internal Error", t, response, messages...)
@@ -0,0 +1,37 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+// Error codes of authentication and authorization failures.
+// They're set as ResponseError.Code of 401 Unauthorized and 403 Forbidden responses
+// returned by built-in authentication handlers and route requirements.
+const (
+	ErrCodeMissingCredentials     = 40101
+	ErrCodeInvalidCredentials     = 40102
+	ErrCodeInvalidSignature       = 40103
+	ErrCodeExpiredCredentials     = 40104
+	ErrCodeCredentialsNotYetValid = 40105
+	ErrCodeReplayedRequest        = 40106
+
+	ErrCodeInvalidIssuer     = 40301
+	ErrCodeInvalidAudience   = 40302
+	ErrCodeInsufficientScope = 40303
+	ErrCodeInsufficientRole  = 40304
+)
+
+// unauthorized creates 401 Unauthorized response with specified error code
+// and WWW-Authenticate challenge.
+func unauthorized(code int, message string, challenge string) *Response {
+	response := Fail(401, nil, ResponseError{Code: code, Message: message})
+	if len(challenge) > 0 {
+		response.WithHeader("WWW-Authenticate", challenge)
+	}
+	return response
+}
+
+// forbidden creates 403 Forbidden response with specified error code.
+func forbidden(code int, message string) *Response {
+	return Fail(403, nil, ResponseError{Code: code, Message: message})
+}
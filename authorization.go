@@ -0,0 +1,95 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+// RequireScopes creates route option which allows requests only from principals
+// granted every specified scope. See RequireRoles for the details on how
+// requirements are checked.
+func RequireScopes(scopes ...string) RouteOption {
+	return func(route *Route) {
+		route.RequiredScopes = append(route.RequiredScopes, scopes...)
+	}
+}
+
+// RequireRoles creates route option which allows requests only from principals
+// having at least one of specified roles.
+// Requirements are checked as soon as principal is stored on request: right after
+// the route handler which stored it, or before the first route handler when it's
+// stored by init request handler. When no principal is stored, requirements are
+// checked before the last route handler, so authentication handlers should end
+// requests without valid credentials themselves.
+// Requests without principal get 401 Unauthorized response, requests of principals
+// which don't meet requirements get 403 Forbidden response listing missing scopes
+// or required roles in error data.
+func RequireRoles(roles ...string) RouteOption {
+	return func(route *Route) {
+		route.RequiredRoles = append(route.RequiredRoles, roles...)
+	}
+}
+
+// authorizedPrincipalKey is a key of principal which met route requirements.
+const authorizedPrincipalKey = "jo.authorized_principal"
+
+// authorizedHandlers returns route handlers wrapped to check route requirements.
+func (route *Route) authorizedHandlers() []RouteHandler {
+	if len(route.RequiredScopes) == 0 && len(route.RequiredRoles) == 0 {
+		return route.Handlers
+	}
+	if len(route.Handlers) == 0 {
+		return []RouteHandler{route.authorize}
+	}
+	handlers := make([]RouteHandler, 0, len(route.Handlers))
+	for i, handler := range route.Handlers {
+		handlers = append(handlers, route.authorizeBefore(handler, i == len(route.Handlers)-1))
+	}
+	return handlers
+}
+
+// authorizeBefore wraps handler, so route requirements are checked before it's
+// called whenever principal stored on request wasn't checked yet. Before the last
+// handler requirements are checked even if there's no principal.
+func (route *Route) authorizeBefore(handler RouteHandler, last bool) RouteHandler {
+	return func(request *Request) *Response {
+		principal := request.Principal()
+		authorized, _ := Value[*Principal](request, authorizedPrincipalKey)
+		if (principal != nil || last) && (principal == nil || principal != authorized) {
+			if response := route.authorize(request); response.EndRequest {
+				return response
+			}
+			request.Set(authorizedPrincipalKey, principal)
+		}
+		return handler(request)
+	}
+}
+
+// authorize checks principal stored on request meets route requirements.
+func (route *Route) authorize(request *Request) *Response {
+	principal := request.Principal()
+	if principal == nil {
+		return unauthorized(ErrCodeMissingCredentials, "Authentication required", "")
+	}
+	var missingScopes []string
+	for _, scope := range route.RequiredScopes {
+		if !principal.HasScope(scope) {
+			missingScopes = append(missingScopes, scope)
+		}
+	}
+	if len(missingScopes) > 0 {
+		response := forbidden(ErrCodeInsufficientScope, "Insufficient scope")
+		response.Error.Data = map[string]interface{}{"missing_scopes": missingScopes}
+		return response
+	}
+	if len(route.RequiredRoles) == 0 {
+		return Next(nil)
+	}
+	for _, role := range route.RequiredRoles {
+		if principal.HasRole(role) {
+			return Next(nil)
+		}
+	}
+	response := forbidden(ErrCodeInsufficientRole, "Insufficient role")
+	response.Error.Data = map[string]interface{}{"required_roles": route.RequiredRoles}
+	return response
+}
@@ -0,0 +1,101 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Stores principal named in X-Principal header on request.
+func testPrincipalHandler(request *Request) *Response {
+	switch request.Context.Request.Header.Get("X-Principal") {
+	case "reader":
+		request.SetPrincipal(&Principal{Subject: "reader", Scopes: []string{"orders:read"}})
+	case "admin":
+		request.SetPrincipal(&Principal{
+			Subject: "admin", Scopes: []string{"orders:read", "orders:write"}, Roles: []string{"admin"}})
+	}
+	return Next(nil)
+}
+
+// Maps routes with required scopes and roles and checks they're enforced.
+func TestRequireScopes(t *testing.T) {
+	api, handlers, _ := newAPITest()
+	api.SetInitRequestHandler(testPrincipalHandler)
+	api.Map("post", "/orders", handlers.emptyHandler).With(RequireScopes("orders:read", "orders:write"))
+	api.Map("delete", "/orders", handlers.emptyHandler).With(RequireRoles("admin", "support"))
+
+	request := func(method string, principal string) (int, string) {
+		recorder := recordRequest(api, method, "/orders", nil, map[string]string{"X-Principal": principal})
+		return recorder.Code, recorder.Body.String()
+	}
+	code, _ := request("POST", "admin")
+	assert.Equal(t, 200, code)
+	code, body := request("POST", "reader")
+	assert.Equal(t, 403, code)
+	assert.Contains(t, body, `"missing_scopes":["orders:write"]`)
+	code, _ = request("POST", "")
+	assert.Equal(t, 401, code)
+
+	code, _ = request("DELETE", "admin")
+	assert.Equal(t, 200, code)
+	code, body = request("DELETE", "reader")
+	assert.Equal(t, 403, code)
+	assert.Contains(t, body, `"required_roles":["admin","support"]`)
+}
+
+// Checks requirements are checked after authentication handler mapped on route.
+func TestRequireScopesAfterHandlers(t *testing.T) {
+	api, handlers, http := newAPITest()
+	api.Map("get", "/orders", func(request *Request) *Response {
+		request.SetPrincipal(&Principal{Scopes: []string{"orders:read"}})
+		return Next(nil)
+	}, handlers.emptyHandler).With(RequireScopes("orders:read"))
+	AssertOk(t, http.Get("/orders"))
+}
+
+// Checks requirements are checked right after principal is stored, so handlers
+// with side effects mapped after authentication don't run for unauthorized requests.
+func TestRequireScopesBeforeSideEffects(t *testing.T) {
+	api, _, _ := newAPITest()
+	created := 0
+	createOrder := func(request *Request) *Response {
+		created++
+		return Next(nil)
+	}
+	audit := func(request *Request) *Response {
+		return Created(created, "/orders/1")
+	}
+	api.Map("post", "/orders", testPrincipalHandler, createOrder, audit).
+		With(RequireScopes("orders:write"))
+
+	recorder := recordRequest(api, "POST", "/orders", nil, map[string]string{"X-Principal": "reader"})
+	assert.Equal(t, 403, recorder.Code)
+	assert.Equal(t, 0, created)
+
+	recorder = recordRequest(api, "POST", "/orders", nil, map[string]string{"X-Principal": "admin"})
+	assert.Equal(t, 201, recorder.Code)
+	assert.Equal(t, 1, created)
+}
+
+// Checks requirements are exposed in route introspection.
+func TestRequirementsIntrospection(t *testing.T) {
+	api, handlers, _ := newAPITest()
+	api.Map("get,post", "/orders", handlers.emptyHandler).
+		With(RequireScopes("orders:read"), RequireScopes("orders:write")).
+		With(RequireRoles("admin"))
+	api.Map("get", "/health", handlers.emptyHandler)
+
+	routes := api.Routes()
+	for _, route := range routes[:2] {
+		assert.Equal(t, []string{"orders:read", "orders:write"}, route.RequiredScopes)
+		assert.Equal(t, []string{"admin"}, route.RequiredRoles)
+		assert.Len(t, route.Handlers, 1)
+	}
+	assert.Empty(t, routes[2].RequiredScopes)
+	assert.Len(t, routes[2].Handlers, 1)
+}
@@ -0,0 +1,143 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Credentials are stored credentials of a user. Only hash of the password is stored.
+type Credentials struct {
+	Username string
+
+	// PasswordHash is a bcrypt hash of the password, see HashPassword.
+	PasswordHash []byte
+
+	// Scopes are permissions granted to the user.
+	Scopes []string
+
+	// Roles are roles assigned to the user.
+	Roles []string
+}
+
+// CredentialStore is a definition of an object which looks user credentials up.
+type CredentialStore interface {
+	// FindCredentials returns credentials of the user or nil if there's no such user.
+	FindCredentials(username string) (*Credentials, error)
+}
+
+// BasicAuthConfig configures HTTP Basic authentication handler.
+type BasicAuthConfig struct {
+	// Store looks credentials up. It's required.
+	Store CredentialStore
+
+	// Realm is sent to clients in WWW-Authenticate header. Default is "Restricted".
+	Realm string
+}
+
+// defBasicAuthRealm is a default realm of Basic authentication.
+const defBasicAuthRealm = "Restricted"
+
+// dummyPasswordHash is compared with passwords of unknown users, so they take
+// as much time to check as passwords of existing users. It's generated on first use.
+var (
+	dummyPasswordHash     []byte
+	dummyPasswordHashOnce sync.Once
+)
+
+// BasicAuth creates a handler which authenticates requests with HTTP Basic
+// authentication. On success username, scopes and roles are stored on request
+// as Principal. Requests without valid credentials get 401 Unauthorized response
+// with WWW-Authenticate challenge. BasicAuth panics if there's no store.
+func BasicAuth(config BasicAuthConfig) RouteHandler {
+	if config.Store == nil {
+		panic("jo: credential store isn't specified")
+	}
+	if len(config.Realm) == 0 {
+		config.Realm = defBasicAuthRealm
+	}
+	challenge := fmt.Sprintf("Basic realm=%q, charset=\"UTF-8\"", config.Realm)
+	return func(request *Request) *Response {
+		username, password, ok := request.Context.Request.BasicAuth()
+		if !ok {
+			return unauthorized(ErrCodeMissingCredentials, "Missing credentials", challenge)
+		}
+		credentials, err := config.Store.FindCredentials(username)
+		if err != nil {
+			return Error(err)
+		}
+		var passwordHash []byte
+		if credentials != nil {
+			passwordHash = credentials.PasswordHash
+		} else {
+			dummyPasswordHashOnce.Do(func() {
+				dummyPasswordHash, _ = HashPassword("dummy password")
+			})
+			passwordHash = dummyPasswordHash
+		}
+		if bcrypt.CompareHashAndPassword(passwordHash, []byte(password)) != nil || credentials == nil {
+			return unauthorized(ErrCodeInvalidCredentials, "Invalid username or password", challenge)
+		}
+		request.SetPrincipal(&Principal{
+			Subject: credentials.Username,
+			Scopes:  credentials.Scopes,
+			Roles:   credentials.Roles,
+		})
+		return Next(nil)
+	}
+}
+
+// HashPassword hashes password with bcrypt to be kept in credential store.
+func HashPassword(password string) ([]byte, error) {
+	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+}
+
+// MemoryCredentialStore keeps credentials in memory of the process.
+// Zero value is an empty store ready to use.
+type MemoryCredentialStore struct {
+	mutex       sync.RWMutex
+	credentials map[string]*Credentials
+}
+
+// NewMemoryCredentialStore creates store with specified credentials.
+func NewMemoryCredentialStore(credentials ...Credentials) *MemoryCredentialStore {
+	store := &MemoryCredentialStore{credentials: make(map[string]*Credentials)}
+	for _, current := range credentials {
+		store.Add(current)
+	}
+	return store
+}
+
+// FindCredentials returns credentials of the user or nil if there's no such user.
+func (store *MemoryCredentialStore) FindCredentials(username string) (*Credentials, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	return store.credentials[username], nil
+}
+
+// Add adds credentials to the store replacing ones of the same user.
+func (store *MemoryCredentialStore) Add(credentials Credentials) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.init()
+	store.credentials[credentials.Username] = &credentials
+}
+
+// Remove removes credentials of the user.
+func (store *MemoryCredentialStore) Remove(username string) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	delete(store.credentials, username)
+}
+
+// init creates map of credentials if it isn't created yet.
+func (store *MemoryCredentialStore) init() {
+	if store.credentials == nil {
+		store.credentials = make(map[string]*Credentials)
+	}
+}
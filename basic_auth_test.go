@@ -0,0 +1,80 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Creates Authorization header with Basic credentials.
+func basicAuthHeader(username string, password string) map[string]string {
+	credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return map[string]string{"Authorization": "Basic " + credentials}
+}
+
+// Authenticates requests with Basic credentials in init request handler.
+func TestBasicAuth(t *testing.T) {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	store := NewMemoryCredentialStore(Credentials{
+		Username: "partner", PasswordHash: passwordHash, Roles: []string{"partner"}})
+	api, _, http := newAPITest()
+	api.SetInitRequestHandler(BasicAuth(BasicAuthConfig{Store: store, Realm: "Partners"}))
+	api.Map("get", "/me", func(request *Request) *Response {
+		assert.True(t, request.Principal().HasRole("partner"))
+		return Ok(request.Principal().Subject)
+	})
+
+	recorder := recordRequest(api, "GET", "/me", nil, basicAuthHeader("partner", "secret"))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "partner")
+
+	response := http.Get("/me")
+	assert.Equal(t, 401, response.HTTPCode)
+	assert.Equal(t, ErrCodeMissingCredentials, response.Error.Code)
+	assert.Equal(t, `Basic realm="Partners", charset="UTF-8"`, response.Headers.Get("WWW-Authenticate"))
+
+	assert.Equal(t, 401, recordRequest(api, "GET", "/me", nil, basicAuthHeader("partner", "wrong")).Code)
+	assert.Equal(t, 401, recordRequest(api, "GET", "/me", nil, basicAuthHeader("unknown", "secret")).Code)
+
+	store.Remove("partner")
+	assert.Equal(t, 401, recordRequest(api, "GET", "/me", nil, basicAuthHeader("partner", "secret")).Code)
+}
+
+// Checks hashed password is verified by bcrypt.
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("secret")
+	assert.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword(hash, []byte("secret")))
+	assert.NotContains(t, string(hash), "secret")
+}
+
+// Checks zero value of memory store is ready to use.
+func TestMemoryCredentialStoreZeroValue(t *testing.T) {
+	store := &MemoryCredentialStore{}
+	credentials, err := store.FindCredentials("alice")
+	assert.NoError(t, err)
+	assert.Nil(t, credentials)
+
+	store.Add(Credentials{Username: "alice"})
+	credentials, err = store.FindCredentials("alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", credentials.Username)
+
+	store.Remove("alice")
+	credentials, _ = store.FindCredentials("alice")
+	assert.Nil(t, credentials)
+}
+
+// Checks handler can't be created without store.
+func TestBasicAuthInvalidConfig(t *testing.T) {
+	assert.PanicsWithValue(t, "jo: credential store isn't specified", func() {
+		BasicAuth(BasicAuthConfig{})
+	})
+}
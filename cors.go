@@ -0,0 +1,220 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/gin-gonic/gin.v1"
+)
+
+// CORSOptions configures cross-origin resource sharing.
+type CORSOptions struct {
+	// AllowedOrigins is a list of origins allowed to make requests, e.g.
+	// "https://example.com". Origin may contain a wildcard, e.g. "https://*.example.com",
+	// and "*" allows any origin.
+	AllowedOrigins []string
+
+	// AllowOriginFunc is called for origins which don't match AllowedOrigins.
+	// Origin is allowed when it returns true.
+	AllowOriginFunc func(origin string) bool
+
+	// AllowedMethods is a list of methods returned on preflight requests.
+	// By default methods mapped on requested path are returned.
+	AllowedMethods []string
+
+	// AllowedHeaders is a list of request headers returned on preflight requests.
+	// By default headers requested by client are returned.
+	AllowedHeaders []string
+
+	// ExposedHeaders is a list of response headers client is allowed to read.
+	ExposedHeaders []string
+
+	// AllowCredentials allows requests with cookies and authorization headers.
+	// It can't be combined with "*" in AllowedOrigins.
+	AllowCredentials bool
+
+	// MaxAge is a duration preflight response can be cached for.
+	// Zero value means no Access-Control-Max-Age header is returned.
+	MaxAge time.Duration
+}
+
+// EnableCORS enables cross-origin resource sharing on every route.
+// Preflight requests are answered automatically on every mapped path
+// unless OPTIONS is mapped on the path explicitly.
+// Options can be overridden on specific route groups via Group.EnableCORS.
+// EnableCORS panics if credentials are allowed from any origin.
+func (api *API) EnableCORS(options CORSOptions) {
+	options.validate()
+	api.cors = &options
+}
+
+// EnableCORS enables cross-origin resource sharing on routes of the group
+// and its nested groups, overriding options set on API or outer groups.
+// EnableCORS panics if credentials are allowed from any origin.
+func (group *Group) EnableCORS(options CORSOptions) {
+	options.validate()
+	group.cors = &options
+}
+
+// validate panics on "*" origin with credentials allowed, which would
+// let any site make requests on behalf of the user.
+func (options *CORSOptions) validate() {
+	if !options.AllowCredentials {
+		return
+	}
+	for _, origin := range options.AllowedOrigins {
+		if origin == "*" {
+			panic("jo: CORS credentials can't be allowed for any origin")
+		}
+	}
+}
+
+// corsOptions returns CORS options of the innermost group of the route
+// or the ones of API. Route is nil for requests which don't match any route.
+// Returns nil when CORS isn't enabled.
+func (api *API) corsOptions(route *Route) *CORSOptions {
+	if route != nil {
+		groups := route.group.lineage()
+		for i := len(groups) - 1; i >= 0; i-- {
+			if groups[i].cors != nil {
+				return groups[i].cors
+			}
+		}
+	}
+	return api.cors
+}
+
+// buildPreflightRoutes maps preflight handler on OPTIONS method of every path
+// with CORS enabled, unless OPTIONS is mapped on the path explicitly.
+func (api *API) buildPreflightRoutes(engine *gin.Engine) {
+	optionsPaths := make(map[string]bool)
+	for _, route := range api.routes {
+		if route.Method == "options" {
+			optionsPaths[route.Path] = true
+		}
+	}
+	for _, route := range api.routes {
+		if optionsPaths[route.Path] || api.corsOptions(route) == nil {
+			continue
+		}
+		preflightRoute := *route
+		preflightRoute.Method = "options"
+		preflightRoute.Handlers = nil
+		mapRouteHandler(&preflightRoute, api.createPreflightHandlerWrapper(&preflightRoute), engine)
+		optionsPaths[route.Path] = true
+	}
+}
+
+// createPreflightHandlerWrapper creates gin-specific handler of preflight requests.
+// Init request handlers aren't called on such requests, end request handlers are.
+func (api *API) createPreflightHandlerWrapper(route *Route) gin.HandlerFunc {
+	return func(innerContext *gin.Context) {
+		request := api.createRequestContext(innerContext, route, Next(nil))
+		response := api.handlePreflight(request)
+		api.endRequest(innerContext, request, response)
+	}
+}
+
+// handlePreflight responds to preflight request with CORS headers.
+// OPTIONS requests which aren't preflight get response with Allow header.
+func (api *API) handlePreflight(request *Request) *Response {
+	var methods []string
+	for _, method := range api.findPathMethods(request.Context.Request.URL.Path) {
+		methods = append(methods, strings.ToUpper(method))
+	}
+	methods = append(methods, "OPTIONS")
+
+	if !isPreflightRequest(request.Context.Request) {
+		return NoContent().WithHeader("Allow", strings.Join(methods, ", "))
+	}
+	header := request.Context.Request.Header
+	options := api.corsOptions(request.route)
+	if !options.allowsOrigin(header.Get("Origin")) {
+		return ForbiddenMessage("Origin is not allowed")
+	}
+
+	response := NoContent()
+	if len(options.AllowedMethods) > 0 {
+		methods = options.AllowedMethods
+	}
+	response.WithHeader("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	if len(options.AllowedHeaders) > 0 {
+		response.WithHeader("Access-Control-Allow-Headers", strings.Join(options.AllowedHeaders, ", "))
+	} else if requestedHeaders := header.Get("Access-Control-Request-Headers"); len(requestedHeaders) > 0 {
+		response.WithHeader("Access-Control-Allow-Headers", requestedHeaders)
+		response.Headers.Add("Vary", "Access-Control-Request-Headers")
+	}
+	if options.MaxAge > 0 {
+		maxAge := strconv.Itoa(int(options.MaxAge / time.Second))
+		response.WithHeader("Access-Control-Max-Age", maxAge)
+	}
+	return response
+}
+
+// writeCORSHeaders writes CORS headers of the response when request origin is allowed.
+func (api *API) writeCORSHeaders(request *Request) {
+	options := api.corsOptions(request.route)
+	origin := request.Context.Request.Header.Get("Origin")
+	if options == nil || len(origin) == 0 || !options.allowsOrigin(origin) {
+		return
+	}
+	header := request.Context.Writer.Header()
+	if options.allowsAnyOrigin() && !options.AllowCredentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Add("Vary", "Origin")
+	}
+	if options.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if len(options.ExposedHeaders) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(options.ExposedHeaders, ", "))
+	}
+}
+
+// allowsOrigin checks whether origin matches allowed origins or function.
+func (options *CORSOptions) allowsOrigin(origin string) bool {
+	for _, allowed := range options.AllowedOrigins {
+		if matchOrigin(allowed, origin) {
+			return true
+		}
+	}
+	return options.AllowOriginFunc != nil && options.AllowOriginFunc(origin)
+}
+
+func (options *CORSOptions) allowsAnyOrigin() bool {
+	for _, allowed := range options.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// matchOrigin matches origin against allowed origin which may contain a wildcard.
+// Origins are compared case-insensitively.
+func matchOrigin(allowed string, origin string) bool {
+	allowed = strings.ToLower(allowed)
+	origin = strings.ToLower(origin)
+	wildcard := strings.IndexByte(allowed, '*')
+	if wildcard < 0 {
+		return allowed == origin
+	}
+	prefix, suffix := allowed[:wildcard], allowed[wildcard+1:]
+	return len(origin) >= len(prefix)+len(suffix) &&
+		strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+}
+
+// isPreflightRequest checks whether request is CORS preflight request.
+func isPreflightRequest(request *http.Request) bool {
+	return request.Method == "OPTIONS" &&
+		len(request.Header.Get("Origin")) > 0 &&
+		len(request.Header.Get("Access-Control-Request-Method")) > 0
+}
@@ -0,0 +1,143 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Enables CORS and checks preflight requests are answered on mapped paths.
+func TestCORSPreflight(t *testing.T) {
+	api, handlers, _ := newAPITest()
+	api.EnableCORS(CORSOptions{
+		AllowedOrigins:   []string{"https://example.com"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+	api.SetInitRequestHandler(func(request *Request) *Response {
+		return Unauthorized()
+	})
+	api.Map("get,post", "/orders", handlers.emptyHandler)
+
+	recorder := recordRequest(api, "OPTIONS", "/orders", nil, map[string]string{
+		"Origin":                         "https://example.com",
+		"Access-Control-Request-Method":  "POST",
+		"Access-Control-Request-Headers": "Authorization, Content-Type",
+	})
+	assert.Equal(t, 204, recorder.Code)
+	header := recorder.Header()
+	assert.Equal(t, "https://example.com", header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", header.Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "GET, POST, HEAD, OPTIONS", header.Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization, Content-Type", header.Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", header.Get("Access-Control-Max-Age"))
+	assert.Contains(t, header["Vary"], "Origin")
+
+	recorder = recordRequest(api, "OPTIONS", "/orders", nil, map[string]string{
+		"Origin":                        "https://evil.com",
+		"Access-Control-Request-Method": "POST",
+	})
+	assert.Equal(t, 403, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+
+	recorder = recordRequest(api, "OPTIONS", "/orders", nil, nil)
+	assert.Equal(t, 204, recorder.Code)
+	assert.Equal(t, "GET, POST, HEAD, OPTIONS", recorder.Header().Get("Allow"))
+}
+
+// Checks CORS headers are added to actual requests from allowed origins only.
+func TestCORSActualRequest(t *testing.T) {
+	api, handlers, _ := newAPITest()
+	api.EnableCORS(CORSOptions{
+		AllowedOrigins: []string{"*"},
+		ExposedHeaders: []string{"X-Request-ID"},
+	})
+	api.Map("get", "/orders", handlers.emptyHandler)
+
+	recorder := recordRequest(api, "GET", "/orders", nil, map[string]string{"Origin": "https://example.com"})
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "*", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-Request-ID", recorder.Header().Get("Access-Control-Expose-Headers"))
+
+	recorder = recordRequest(api, "GET", "/missing", nil, map[string]string{"Origin": "https://example.com"})
+	assert.Equal(t, 404, recorder.Code)
+	assert.Equal(t, "*", recorder.Header().Get("Access-Control-Allow-Origin"))
+
+	recorder = recordRequest(api, "GET", "/orders", nil, nil)
+	assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+}
+
+// Checks options of route group override the ones of API.
+func TestCORSGroup(t *testing.T) {
+	api, handlers, _ := newAPITest()
+	api.EnableCORS(CORSOptions{AllowedOrigins: []string{"https://example.com"}})
+	admin := api.Group("/admin")
+	admin.EnableCORS(CORSOptions{
+		AllowOriginFunc: func(origin string) bool {
+			return strings.HasSuffix(origin, ".admin.com")
+		},
+		AllowedMethods: []string{"GET"},
+	})
+	admin.Group("/users").Map("get", "/", handlers.emptyHandler)
+	api.Map("get", "/orders", handlers.emptyHandler)
+
+	preflight := map[string]string{
+		"Origin":                        "https://panel.admin.com",
+		"Access-Control-Request-Method": "GET",
+	}
+	recorder := recordRequest(api, "OPTIONS", "/admin/users/", nil, preflight)
+	assert.Equal(t, 204, recorder.Code)
+	assert.Equal(t, "https://panel.admin.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET", recorder.Header().Get("Access-Control-Allow-Methods"))
+
+	recorder = recordRequest(api, "OPTIONS", "/orders", nil, preflight)
+	assert.Equal(t, 403, recorder.Code)
+}
+
+// Checks explicit OPTIONS routes aren't replaced by preflight handler.
+func TestCORSExplicitOptions(t *testing.T) {
+	api, _, _ := newAPITest()
+	api.EnableCORS(CORSOptions{AllowedOrigins: []string{"*"}})
+	api.Map("options", "/orders", func(request *Request) *Response {
+		return Ok("custom")
+	})
+
+	recorder := recordRequest(api, "OPTIONS", "/orders", nil, map[string]string{
+		"Origin":                        "https://example.com",
+		"Access-Control-Request-Method": "GET",
+	})
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "custom")
+	assert.Equal(t, "*", recorder.Header().Get("Access-Control-Allow-Origin"))
+}
+
+// Checks matching of origins with wildcards.
+func TestMatchOrigin(t *testing.T) {
+	assert.True(t, matchOrigin("*", "https://example.com"))
+	assert.True(t, matchOrigin("https://example.com", "https://EXAMPLE.com"))
+	assert.True(t, matchOrigin("https://*.example.com", "https://api.example.com"))
+	assert.False(t, matchOrigin("https://*.example.com", "https://example.com"))
+	assert.False(t, matchOrigin("https://*.example.com", "https://api.example.org"))
+	assert.False(t, matchOrigin("https://example.com", "http://example.com"))
+}
+
+// Makes sure credentials can't be allowed for any origin.
+func TestCORSCredentialsWithAnyOrigin(t *testing.T) {
+	api := NewAPI()
+	options := CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true}
+	assert.Panics(t, func() {
+		api.EnableCORS(options)
+	})
+	assert.Panics(t, func() {
+		api.Group("/v1").EnableCORS(options)
+	})
+	assert.NotPanics(t, func() {
+		api.EnableCORS(CORSOptions{AllowedOrigins: []string{"https://*.example.com"}, AllowCredentials: true})
+	})
+}
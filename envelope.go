@@ -0,0 +1,253 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// EnvelopeRenderer is a definition of an object which writes response to client.
+// It's responsible for HTTP status, headers and body.
+// By default every response is rendered by StandardRenderer.
+type EnvelopeRenderer interface {
+	Render(request *Request, response *Response)
+}
+
+// Envelope is a format responses are wrapped in.
+// Every envelope is rendered by one of built-in renderers.
+type Envelope int
+
+const (
+	// StandardEnvelope is rendered by StandardRenderer.
+	StandardEnvelope Envelope = iota + 1
+
+	// ProblemEnvelope is rendered by ProblemRenderer.
+	ProblemEnvelope
+
+	// JSONAPIEnvelope is rendered by JSONAPIRenderer.
+	JSONAPIEnvelope
+
+	// DataOnlyEnvelope is rendered by DataOnlyRenderer.
+	DataOnlyEnvelope
+)
+
+// Content types of rendered responses.
+const (
+	jsonContentType    = "application/json; charset=utf-8"
+	problemContentType = "application/problem+json; charset=utf-8"
+	jsonAPIContentType = "application/vnd.api+json"
+)
+
+// SetRenderer sets renderer of every response. Default is StandardRenderer.
+// It can be overridden on specific routes via WithRenderer option.
+func (api *API) SetRenderer(renderer EnvelopeRenderer) {
+	api.renderer = renderer
+}
+
+// SetEnvelope sets format of every response. It's a shortcut for SetRenderer
+// with built-in renderer of the envelope.
+func (api *API) SetEnvelope(envelope Envelope) {
+	api.SetRenderer(envelope.renderer())
+}
+
+// WithRenderer creates route option which sets renderer of route responses.
+func WithRenderer(renderer EnvelopeRenderer) RouteOption {
+	return func(route *Route) {
+		route.renderer = renderer
+	}
+}
+
+// WithEnvelope creates route option which sets format of route responses.
+// It's a shortcut for WithRenderer with built-in renderer of the envelope.
+func WithEnvelope(envelope Envelope) RouteOption {
+	return WithRenderer(envelope.renderer())
+}
+
+func (envelope Envelope) renderer() EnvelopeRenderer {
+	switch envelope {
+	case ProblemEnvelope:
+		return ProblemRenderer{}
+	case JSONAPIEnvelope:
+		return JSONAPIRenderer{}
+	case DataOnlyEnvelope:
+		return DataOnlyRenderer{}
+	}
+	return StandardRenderer{}
+}
+
+// render writes response headers, cookies and CORS headers and then
+// writes response via renderer configured for the route or API.
+// 204 No Content responses are written without body.
+func (api *API) render(request *Request, response *Response) {
+	writer := request.Context.Writer
+	for name, values := range response.Headers {
+		writer.Header()[name] = values
+	}
+	for _, cookie := range response.Cookies {
+		http.SetCookie(writer, cookie)
+	}
+	api.writeCORSHeaders(request)
+	if response.HTTPCode == http.StatusNoContent {
+		request.Context.Status(response.HTTPCode)
+		writer.WriteHeaderNow()
+		return
+	}
+	renderer := api.renderer
+	if request.route != nil && request.route.renderer != nil {
+		renderer = request.route.renderer
+	}
+	if renderer == nil {
+		renderer = StandardRenderer{}
+	}
+	renderer.Render(request, response)
+}
+
+// StandardRenderer wraps every response in {successful, data, error} object.
+type StandardRenderer struct{}
+
+// Render writes response wrapped in standard envelope.
+// NOTE the response itself is not serialized because we should hide error field
+// on successful responses.
+func (StandardRenderer) Render(request *Request, response *Response) {
+	document := make(map[string]interface{})
+	document["successful"] = response.Successful
+	document["data"] = response.Data
+	if !response.Successful {
+		document["error"] = response.Error
+	}
+	WriteJSON(request, response.HTTPCode, jsonContentType, document)
+}
+
+// ProblemRenderer renders failed responses as RFC 7807 problem details
+// with application/problem+json content type. Members of ResponseError.Data are
+// added as extension members if it's an object, otherwise it's added as "data" member.
+// Error code is added as "code" member when it differs from HTTP code,
+// request ID is added as "request_id" member.
+// Successful responses are rendered by StandardRenderer.
+type ProblemRenderer struct{}
+
+// Render writes response as problem details.
+func (ProblemRenderer) Render(request *Request, response *Response) {
+	if response.Successful {
+		StandardRenderer{}.Render(request, response)
+		return
+	}
+	document := make(map[string]interface{})
+	if extensions := toJSONObject(response.Error.Data); extensions != nil {
+		for name, value := range extensions {
+			document[name] = value
+		}
+	} else if response.Error.Data != nil {
+		document["data"] = response.Error.Data
+	}
+	if response.Error.Code != response.HTTPCode {
+		document["code"] = response.Error.Code
+	}
+	document["type"] = "about:blank"
+	document["title"] = http.StatusText(response.HTTPCode)
+	document["status"] = response.HTTPCode
+	if len(response.Error.Message) > 0 {
+		document["detail"] = response.Error.Message
+	}
+	document["instance"] = request.Context.Request.URL.Path
+	if len(response.Error.RequestID) > 0 {
+		document["request_id"] = response.Error.RequestID
+	}
+	WriteJSON(request, response.HTTPCode, problemContentType, document)
+}
+
+// JSONAPIRenderer renders responses as JSON:API documents https://jsonapi.org
+// Successful response data is rendered as top-level "data" member,
+// failed responses are rendered as "errors" member with a single error object
+// identified by request ID.
+type JSONAPIRenderer struct{}
+
+// Render writes response as JSON:API document.
+func (JSONAPIRenderer) Render(request *Request, response *Response) {
+	document := make(map[string]interface{})
+	if response.Successful {
+		document["data"] = response.Data
+	} else {
+		errorObject := make(map[string]interface{})
+		if len(response.Error.RequestID) > 0 {
+			errorObject["id"] = response.Error.RequestID
+		}
+		errorObject["status"] = strconv.Itoa(response.HTTPCode)
+		errorObject["code"] = strconv.Itoa(response.Error.Code)
+		errorObject["title"] = http.StatusText(response.HTTPCode)
+		errorObject["detail"] = response.Error.Message
+		if response.Error.Data != nil {
+			errorObject["meta"] = response.Error.Data
+		}
+		document["errors"] = []interface{}{errorObject}
+	}
+	WriteJSON(request, response.HTTPCode, jsonAPIContentType, document)
+}
+
+// DataOnlyRenderer renders bare response data without any envelope.
+// Failed responses are rendered as {code, message, data} error object.
+type DataOnlyRenderer struct{}
+
+// Render writes response data.
+func (DataOnlyRenderer) Render(request *Request, response *Response) {
+	if response.Successful {
+		WriteJSON(request, response.HTTPCode, jsonContentType, response.Data)
+		return
+	}
+	WriteJSON(request, response.HTTPCode, jsonContentType, response.Error)
+}
+
+// WriteJSON serializes body to JSON and writes it with specified HTTP code
+// and content type. On HEAD requests only headers are written and Content-Length
+// is set to the length of body which would be returned on GET request.
+// When body can't be serialized, internal error is written in standard envelope instead.
+// It's meant to be used by renderers.
+func WriteJSON(request *Request, code int, contentType string, body interface{}) {
+	jsonBytes, err := json.Marshal(body)
+	if err != nil {
+		errorResponse := ErrorMessage("Couldn't serialize response")
+		errorResponse.Error.RequestID = request.ID()
+		code = errorResponse.HTTPCode
+		contentType = jsonContentType
+		jsonBytes, _ = json.Marshal(map[string]interface{}{
+			"successful": false,
+			"data":       nil,
+			"error":      errorResponse.Error,
+		})
+		if request.Logger != nil {
+			request.Logger.Error("Couldn't serialize response: %v", err)
+		}
+	}
+	innerContext := request.Context
+	if innerContext.Request.Method == "HEAD" {
+		innerContext.Header("Content-Type", contentType)
+		innerContext.Header("Content-Length", strconv.Itoa(len(jsonBytes)))
+		innerContext.Status(code)
+		innerContext.Writer.WriteHeaderNow()
+		return
+	}
+	innerContext.Data(code, contentType, jsonBytes)
+}
+
+// toJSONObject converts value to a map if it's serialized as JSON object.
+func toJSONObject(value interface{}) map[string]interface{} {
+	if value == nil {
+		return nil
+	}
+	if object, ok := value.(map[string]interface{}); ok {
+		return object
+	}
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var object map[string]interface{}
+	if json.Unmarshal(jsonBytes, &object) != nil {
+		return nil
+	}
+	return object
+}
@@ -0,0 +1,144 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Sends request to API and parses JSON response body into map.
+func recordJSON(t *testing.T, api *API, method string, url string) map[string]interface{} {
+	recorder := recordRequest(api, method, url, nil, nil)
+	body := make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	return body
+}
+
+// Creates API with problem envelope and checks failed response is rendered as RFC 7807.
+func TestProblemEnvelope(t *testing.T) {
+	api, handlers, _ := newAPITest()
+	api.SetEnvelope(ProblemEnvelope)
+	api.Map("get", "/ok", handlers.emptyHandler)
+	api.Map("get", "/orders/:id", func(request *Request) *Response {
+		errorData := ResponseError{
+			Code: 1001, Message: "Order is locked", Data: map[string]interface{}{"order": "1"}}
+		return Fail(409, nil, errorData)
+	})
+	api.Map("post", "/orders", func(request *Request) *Response {
+		return BadRequestError(request.Bind(&testOrder{}))
+	})
+
+	recorder := recordRequest(api, "GET", "/orders/1", nil, nil)
+	assert.Equal(t, 409, recorder.Code)
+	assert.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+	expected := map[string]interface{}{
+		"type":     "about:blank",
+		"title":    "Conflict",
+		"status":   float64(409),
+		"detail":   "Order is locked",
+		"instance": "/orders/1",
+		"code":     float64(1001),
+		"order":    "1",
+	}
+	problem := make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	expected["request_id"] = recorder.Header().Get("X-Request-ID")
+	assert.Equal(t, expected, problem)
+
+	recorder = recordRequest(api, "POST", "/orders", map[string]string{"name": "Order"}, nil)
+	problem = make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, "Validation failed", problem["detail"])
+	assert.NotEmpty(t, problem["data"])
+
+	assert.Equal(t, true, recordJSON(t, api, "GET", "/ok")["successful"])
+}
+
+// Creates API with problem envelope on a single route.
+func TestProblemEnvelopeRoute(t *testing.T) {
+	api, _, http := newAPITest()
+	forbidden := func(request *Request) *Response {
+		return Forbidden()
+	}
+	api.Map("get", "/problem", forbidden).With(WithEnvelope(ProblemEnvelope))
+	api.Map("get", "/standard", forbidden)
+
+	assert.Equal(t, "Forbidden", recordJSON(t, api, "GET", "/problem")["title"])
+	AssertForbidden(t, http.Get("/standard"))
+}
+
+// Creates API with JSON:API renderer and checks successful and failed documents.
+func TestJSONAPIRenderer(t *testing.T) {
+	api, handlers, _ := newAPITest()
+	api.SetRenderer(JSONAPIRenderer{})
+	api.Map("get", "/ok", handlers.emptyMessageHandler)
+	api.Map("get", "/fail", func(request *Request) *Response {
+		return Fail(422, nil, ResponseError{Code: 7, Message: "Invalid", Data: "meta"})
+	})
+
+	recorder := recordRequest(api, "GET", "/ok", nil, nil)
+	assert.Equal(t, jsonAPIContentType, recorder.Header().Get("Content-Type"))
+	assert.Equal(t, map[string]interface{}{"data": "hello"}, recordJSON(t, api, "GET", "/ok"))
+
+	recorder = recordRequest(api, "GET", "/fail", nil, nil)
+	body := make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	expectedError := map[string]interface{}{
+		"id":     recorder.Header().Get("X-Request-ID"),
+		"status": "422",
+		"code":   "7",
+		"title":  "Unprocessable Entity",
+		"detail": "Invalid",
+		"meta":   "meta",
+	}
+	expected := map[string]interface{}{"errors": []interface{}{expectedError}}
+	assert.Equal(t, expected, body)
+}
+
+// Creates API with data only envelope and checks bare data is rendered.
+func TestDataOnlyRenderer(t *testing.T) {
+	api, handlers, _ := newAPITest()
+	api.SetEnvelope(DataOnlyEnvelope)
+	api.Map("get", "/ok", handlers.emptyMessageHandler)
+	api.Map("get", "/forbidden", func(request *Request) *Response {
+		return Forbidden()
+	})
+
+	recorder := recordRequest(api, "GET", "/ok", nil, nil)
+	assert.Equal(t, `"hello"`, recorder.Body.String())
+
+	recorder = recordRequest(api, "GET", "/forbidden", nil, nil)
+	body := make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	expected := map[string]interface{}{
+		"code":       float64(403),
+		"message":    "Forbidden",
+		"data":       nil,
+		"request_id": recorder.Header().Get("X-Request-ID"),
+	}
+	assert.Equal(t, expected, body)
+}
+
+type testHeaderRenderer struct{}
+
+func (testHeaderRenderer) Render(request *Request, response *Response) {
+	request.Context.Header("X-Rendered", "custom")
+	WriteJSON(request, response.HTTPCode, "application/json", response.Data)
+}
+
+// Creates API with custom renderer on a single route.
+func TestCustomRendererRoute(t *testing.T) {
+	api, handlers, http := newAPITest()
+	api.Map("get", "/custom", handlers.emptyMessageHandler).With(WithRenderer(testHeaderRenderer{}))
+	api.Map("get", "/standard", handlers.emptyMessageHandler)
+
+	recorder := recordRequest(api, "GET", "/custom", nil, nil)
+	assert.Equal(t, "custom", recorder.Header().Get("X-Rendered"))
+	assert.Equal(t, `"hello"`, recorder.Body.String())
+	AssertOk(t, http.Get("/standard"))
+}
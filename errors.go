@@ -0,0 +1,96 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"context"
+	"errors"
+)
+
+// HTTPError is an error which knows how it should be represented in response.
+// When returned to Error directly or wrapped into other errors it results in
+// response with HTTP code, message and data of the error.
+type HTTPError struct {
+	Code    int
+	Message string
+	Data    interface{}
+
+	// Err is an optional underlying error.
+	Err error
+}
+
+// NewHTTPError creates HTTP error with specified code and message.
+func NewHTTPError(code int, message string) *HTTPError {
+	return &HTTPError{Code: code, Message: message}
+}
+
+// Error returns error message.
+func (err *HTTPError) Error() string {
+	if err.Err != nil {
+		return err.Message + ": " + err.Err.Error()
+	}
+	return err.Message
+}
+
+// Unwrap returns underlying error.
+func (err *HTTPError) Unwrap() error {
+	return err.Err
+}
+
+// HasCode reports whether err is or wraps HTTP error with specified code.
+func HasCode(err error, code int) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.Code == code
+}
+
+// errorMapping maps errors matching target to HTTP error.
+type errorMapping struct {
+	target    error
+	httpError *HTTPError
+}
+
+// RegisterError makes responses created by Error from errors matching target
+// (see errors.Is) return specified HTTP code and message, e.g.
+//
+//	api.RegisterError(sql.ErrNoRows, 404, "Not Found")
+//
+// Errors are matched in the order they were registered. Registered errors
+// are consulted only for errors which don't wrap HTTPError.
+func (api *API) RegisterError(target error, code int, message string) {
+	mapping := errorMapping{target: target, httpError: NewHTTPError(code, message)}
+	api.errorMappings = append(api.errorMappings, mapping)
+}
+
+// resolveError replaces internal error response with the one registered for its error.
+// Context errors which aren't registered result in 504 Gateway Timeout
+// when deadline is exceeded and 503 Service Unavailable when context is canceled.
+// In production mode messages of other errors which aren't registered are hidden.
+func (api *API) resolveError(response *Response) *Response {
+	if response.err == nil {
+		return response
+	}
+	var resolved *Response
+	for _, mapping := range api.errorMappings {
+		if errors.Is(response.err, mapping.target) {
+			resolved = createHTTPErrorResponse(mapping.httpError.Code, mapping.httpError.Message)
+			break
+		}
+	}
+	if resolved == nil && errors.Is(response.err, context.DeadlineExceeded) {
+		resolved = GatewayTimeout()
+	}
+	if resolved == nil && errors.Is(response.err, context.Canceled) {
+		resolved = ServiceUnavailable()
+	}
+	if resolved != nil {
+		return mergeResponseHeaders(resolved, response)
+	}
+	if api.productionMode && response.Error.Message != "Internal Error" {
+		hidden := *response
+		hidden.Error.Message = "Internal Error"
+		return &hidden
+	}
+	return response
+}
@@ -0,0 +1,96 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTestNoRows = errors.New("no rows in result set")
+
+// Creates responses from HTTP errors, directly and wrapped.
+func TestErrorFromHTTPError(t *testing.T) {
+	httpErr := &HTTPError{Code: 409, Message: "Order exists", Data: "order-1"}
+	for _, err := range []error{httpErr, fmt.Errorf("create order: %w", httpErr)} {
+		response := Error(err)
+		AssertHTTPError(409, "", t, response, "Order exists")
+		assert.Equal(t, "order-1", response.Error.Data)
+	}
+}
+
+// Checks HTTP errors matching by identity and code, and unwrapping.
+func TestHTTPErrorIs(t *testing.T) {
+	errUserNotFound := &HTTPError{Code: 404, Message: "User not found", Err: errTestNoRows}
+	err := fmt.Errorf("lookup: %w", errUserNotFound)
+	assert.True(t, errors.Is(err, errUserNotFound))
+	assert.False(t, errors.Is(err, NewHTTPError(404, "User not found")))
+	assert.True(t, HasCode(err, 404))
+	assert.False(t, HasCode(err, 400))
+	assert.False(t, HasCode(errTestNoRows, 404))
+	assert.True(t, errors.Is(err, errTestNoRows))
+	assert.Equal(t, "lookup: User not found: no rows in result set", err.Error())
+}
+
+// Creates API with registered sentinel error and checks it's mapped on response.
+func TestRegisterError(t *testing.T) {
+	api, _, http := newAPITest()
+	api.RegisterError(errTestNoRows, 404, "Not Found")
+	api.Map("get", "/users/:id", func(request *Request) *Response {
+		return Error(fmt.Errorf("user %s: %w", request.Param("id"), errTestNoRows))
+	})
+	api.Map("get", "/fail", func(request *Request) *Response {
+		return Error(errors.New("connection refused"))
+	})
+
+	AssertNotFound(t, http.Get("/users/1"))
+	AssertResponseError(t, http.Get("/fail"), "connection refused")
+}
+
+// Creates API in production mode and checks unregistered error messages are hidden.
+func TestErrorProductionMode(t *testing.T) {
+	api, _, http := newAPITest()
+	api.SetProductionMode(true)
+	api.Map("get", "/fail", func(request *Request) *Response {
+		return Error(errors.New("connection refused"))
+	})
+	api.Map("get", "/forbidden", func(request *Request) *Response {
+		return Error(NewHTTPError(403, "No access"))
+	})
+
+	AssertResponseError(t, http.Get("/fail"), "Internal Error")
+	AssertForbidden(t, http.Get("/forbidden"), "No access")
+}
+
+// Creates API with end request handlers returning errors. Registered errors must be
+// resolved before end request handler sees the response and after it returns one.
+func TestRegisterErrorEndRequestHandler(t *testing.T) {
+	api, _, http := newAPITest()
+	api.SetProductionMode(true)
+	api.RegisterError(errTestNoRows, 404, "Not Found")
+	api.SetEndRequestHandler(func(request *Request) *Response {
+		switch request.Context.Request.URL.Path {
+		case "/users":
+			return Error(errTestNoRows)
+		case "/fail":
+			return Error(errors.New("connection refused"))
+		}
+		assert.Equal(t, 404, request.PrevHandlerResponse.HTTPCode)
+		return request.PrevHandlerResponse
+	})
+	handler := func(request *Request) *Response {
+		return Error(errTestNoRows)
+	}
+	api.Map("get", "/users", handler)
+	api.Map("get", "/fail", handler)
+	api.Map("get", "/users/:id", handler)
+
+	AssertNotFound(t, http.Get("/users"))
+	AssertResponseError(t, http.Get("/fail"), "Internal Error")
+	AssertNotFound(t, http.Get("/users/1"))
+}
@@ -0,0 +1,95 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import "path"
+
+// Group is a set of routes sharing common path prefix and chain of handlers.
+// Groups can be nested, in which case prefixes and handlers are composed
+// from the outermost group to the innermost one.
+type Group struct {
+	api                *API
+	parent             *Group
+	prefix             string
+	handlers           []RouteHandler
+	initRequestHandler RouteHandler
+	endRequestHandler  RouteHandler
+	cors               *CORSOptions
+}
+
+// Group creates route group with specified path prefix and handlers.
+// Group handlers are called before route handlers on every route mapped via the group.
+func (api *API) Group(prefix string, handlers ...RouteHandler) *Group {
+	return newGroup(api, nil, prefix, handlers)
+}
+
+// Group creates nested route group. Prefix and handlers of the nested group
+// are appended to the ones of its parent.
+func (group *Group) Group(prefix string, handlers ...RouteHandler) *Group {
+	return newGroup(group.api, group, prefix, handlers)
+}
+
+// SetInitRequestHandler sets a handler function which will be called on every route
+// of the group right after API-level init request handler and init request handlers
+// of outer groups.
+func (group *Group) SetInitRequestHandler(handler RouteHandler) {
+	group.initRequestHandler = handler
+}
+
+// SetEndRequestHandler sets a handler function which will be called on every route
+// of the group right before end request handlers of outer groups and API-level
+// end request handler.
+func (group *Group) SetEndRequestHandler(handler RouteHandler) {
+	group.endRequestHandler = handler
+}
+
+// Map assigns a chain of handlers to a URL path relative to group prefix.
+// Group handlers are prepended to the chain.
+// See API.Map for the details on arguments.
+func (group *Group) Map(httpMethods string, relativePath string, handlers ...RouteHandler) RouteList {
+	fullPath := joinPaths(group.prefix, relativePath)
+	return group.api.addRoutes(httpMethods, fullPath, group, handlers)
+}
+
+// lineage returns the group and all its parents ordered from the outermost one.
+func (group *Group) lineage() []*Group {
+	var groups []*Group
+	for current := group; current != nil; current = current.parent {
+		groups = append([]*Group{current}, groups...)
+	}
+	return groups
+}
+
+func (group *Group) combineHandlers(handlers []RouteHandler) []RouteHandler {
+	combined := make([]RouteHandler, 0, len(group.handlers)+len(handlers))
+	combined = append(combined, group.handlers...)
+	combined = append(combined, handlers...)
+	return combined
+}
+
+func newGroup(api *API, parent *Group, prefix string, handlers []RouteHandler) *Group {
+	group := &Group{}
+	group.api = api
+	group.parent = parent
+	group.prefix = prefix
+	group.handlers = handlers
+	if parent != nil {
+		group.prefix = joinPaths(parent.prefix, prefix)
+		group.handlers = parent.combineHandlers(handlers)
+	}
+	return group
+}
+
+// joinPaths concatenates URL paths keeping trailing slash of the relative path.
+func joinPaths(absolutePath, relativePath string) string {
+	if len(relativePath) == 0 {
+		return absolutePath
+	}
+	finalPath := path.Join(absolutePath, relativePath)
+	if relativePath[len(relativePath)-1] == '/' && finalPath[len(finalPath)-1] != '/' {
+		return finalPath + "/"
+	}
+	return finalPath
+}
@@ -0,0 +1,81 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates nested groups and makes sure prefixes and handlers are composed.
+func TestGroupMap(t *testing.T) {
+	api, handlers, _ := newAPITest()
+	admin := api.Group("/v1/admin", handlers.authHandler)
+	admin.Map("get", "/users", handlers.emptyHandler)
+	reports := admin.Group("reports", handlers.validateToken)
+	reports.Map("get,post", "/daily/", handlers.emptyHandler)
+
+	assert.Equal(t, 3, len(api.routes))
+	assert.Equal(t, "/v1/admin/users", api.routes[0].Path)
+	assert.Equal(t, 2, len(api.routes[0].Handlers))
+	assert.Equal(t, "/v1/admin/reports/daily/", api.routes[1].Path)
+	assert.Equal(t, 3, len(api.routes[1].Handlers))
+}
+
+// Creates group with auth handler and makes sure it's called before route handler.
+func TestGroupHandlers(t *testing.T) {
+	api, handlers, http := newAPITest()
+	api.Group("/secured", handlers.authHandler).Map("get", "/area", handlers.emptyHandler)
+
+	response := http.Get("/secured/area")
+	AssertUnauthorized(t, response)
+
+	response = http.Get("/secured/area?token=secret")
+	AssertOk(t, response)
+}
+
+// Sets init and end request handlers on API and nested groups
+// and checks the order they're called in.
+func TestGroupInitEndRequestHandlers(t *testing.T) {
+	api, _, http := newAPITest()
+	var calls []string
+	track := func(name string) RouteHandler {
+		return func(request *Request) *Response {
+			calls = append(calls, name)
+			return request.PrevHandlerResponse
+		}
+	}
+	api.SetInitRequestHandler(track("api-init"))
+	api.SetEndRequestHandler(track("api-end"))
+	outer := api.Group("/outer")
+	outer.SetInitRequestHandler(track("outer-init"))
+	outer.SetEndRequestHandler(track("outer-end"))
+	inner := outer.Group("/inner")
+	inner.SetInitRequestHandler(track("inner-init"))
+	inner.SetEndRequestHandler(track("inner-end"))
+	inner.Map("get", "/", func(request *Request) *Response {
+		calls = append(calls, "handler")
+		return Ok(true)
+	})
+
+	response := http.Get("/outer/inner/")
+
+	AssertOk(t, response)
+	expected := []string{
+		"api-init", "outer-init", "inner-init", "handler", "inner-end", "outer-end", "api-end"}
+	assert.Equal(t, expected, calls)
+}
+
+// Makes sure group init request handler can end request.
+func TestGroupInitRequestHandlerEndsRequest(t *testing.T) {
+	api, handlers, http := newAPITest()
+	group := api.Group("/group")
+	group.SetInitRequestHandler(handlers.validateToken)
+	group.Map("get", "/path", handlers.emptyHandler)
+
+	response := http.Get("/group/path")
+	AssertForbidden(t, response, "Token required")
+}
@@ -0,0 +1,70 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import "reflect"
+
+// NoInput is used as input type of typed handlers which don't expect request body.
+type NoInput struct{}
+
+// Handle creates route handler from a function which takes request input
+// of specific type and returns output of specific type or error.
+// Input is bound from JSON request body via Request.Bind, or from query string
+// via Request.BindQuery on GET, HEAD, DELETE and OPTIONS requests.
+// Pointer input is allocated before binding. Binding is skipped if input type is NoInput.
+// Binding error results in BadRequest response, error returned by the function
+// results in response created by Error. Output is wrapped in Ok response.
+// Input and output types are recorded on routes via WithTypes option, e.g.
+//
+//	api.Map("post", "/orders", Handle(createOrder)).With(WithTypes(createOrder))
+func Handle[In any, Out any](handler func(request *Request, input In) (Out, error)) RouteHandler {
+	return func(request *Request) *Response {
+		var input In
+		if err := bindInput(request, &input); err != nil {
+			return BadRequestError(err)
+		}
+		output, err := handler(request, input)
+		if err != nil {
+			return Error(err)
+		}
+		return Ok(output)
+	}
+}
+
+// WithTypes creates route option which records input and output types
+// of a function passed to Handle on the route. When it's applied several times,
+// types of the last function are kept.
+func WithTypes[In any, Out any](handler func(request *Request, input In) (Out, error)) RouteOption {
+	input := reflect.TypeOf((*In)(nil)).Elem()
+	output := reflect.TypeOf((*Out)(nil)).Elem()
+	return func(route *Route) {
+		route.Input = input
+		route.Output = output
+	}
+}
+
+// bindInput binds request data into input of typed handler. Input is a pointer
+// to handler input; when input itself is a nil pointer, value is allocated for it.
+func bindInput(request *Request, input interface{}) error {
+	inputValue := reflect.ValueOf(input).Elem()
+	if inputValue.Kind() == reflect.Ptr && inputValue.IsNil() {
+		inputValue.Set(reflect.New(inputValue.Type().Elem()))
+	}
+	if inputValue.Kind() == reflect.Ptr {
+		input = inputValue.Interface()
+		inputValue = inputValue.Elem()
+	}
+	if _, ok := input.(*NoInput); ok {
+		return nil
+	}
+	switch request.Context.Request.Method {
+	case "GET", "HEAD", "DELETE", "OPTIONS":
+		if inputValue.Kind() == reflect.Struct {
+			return request.BindQuery(input)
+		}
+		return nil
+	}
+	return request.Bind(input)
+}
@@ -0,0 +1,97 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCreateOrderOutput struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func createTestOrder(request *Request, input testOrder) (testCreateOrderOutput, error) {
+	if input.Name == "Broken" {
+		return testCreateOrderOutput{}, errors.New("Order is broken")
+	}
+	return testCreateOrderOutput{ID: 1, Name: input.Name}, nil
+}
+
+// Creates API with typed handler and checks binding, output and errors.
+func TestHandle(t *testing.T) {
+	api, _, http := newAPITest()
+	path := "/orders"
+	api.Map("post", path, Handle(createTestOrder))
+
+	response := http.Post(path, newValidTestOrder())
+	AssertOk(t, response)
+	assert.Equal(t, map[string]interface{}{"id": float64(1), "name": "Order"}, response.Data)
+
+	response = http.Post(path, nil)
+	AssertBadRequest(t, response, ErrEmptyBody.Error())
+
+	brokenOrder := newValidTestOrder()
+	brokenOrder.Name = "Broken"
+	response = http.Post(path, brokenOrder)
+	AssertResponseError(t, response, "Order is broken")
+}
+
+// Creates API with typed handlers reading query string and having no input.
+func TestHandleQueryAndNoInput(t *testing.T) {
+	api, _, http := newAPITest()
+	api.Map("get", "/search", Handle(func(request *Request, query testSearchQuery) (int, error) {
+		return query.Limit, nil
+	}))
+	api.Map("post", "/ping", Handle(func(request *Request, input NoInput) (string, error) {
+		return "pong", nil
+	}))
+
+	response := http.Get("/search?limit=5")
+	AssertOk(t, response)
+	assert.Equal(t, float64(5), response.Data)
+
+	response = http.Post("/ping", nil)
+	AssertOk(t, response)
+	assert.Equal(t, "pong", response.Data)
+}
+
+// Maps typed handler and checks input and output types recorded on route.
+func TestHandleRouteTypes(t *testing.T) {
+	api, handlers, _ := newAPITest()
+	api.Map("post", "/orders", handlers.emptyHandler, Handle(createTestOrder)).
+		With(WithTypes(createTestOrder))
+	api.Map("get", "/plain", handlers.emptyHandler)
+
+	routes := api.Routes()
+	assert.Equal(t, 2, len(routes[0].Handlers))
+	assert.Equal(t, reflect.TypeOf(testOrder{}), routes[0].Input)
+	assert.Equal(t, reflect.TypeOf(testCreateOrderOutput{}), routes[0].Output)
+	assert.Nil(t, routes[1].Input)
+	assert.Nil(t, routes[1].Output)
+}
+
+// Creates API with typed handlers taking pointer input. Input must be allocated
+// before binding on every method.
+func TestHandlePointerInput(t *testing.T) {
+	api, _, http := newAPITest()
+	search := func(request *Request, query *testSearchQuery) (int, error) {
+		return query.Limit, nil
+	}
+	api.Map("get,post", "/search", Handle(search)).With(WithTypes(search))
+
+	response := http.Get("/search?limit=5")
+	AssertOk(t, response)
+	assert.Equal(t, float64(5), response.Data)
+
+	response = http.Post("/search", map[string]interface{}{"Limit": 7, "Sort": "date"})
+	AssertOk(t, response)
+	assert.Equal(t, float64(7), response.Data)
+	assert.Equal(t, reflect.TypeOf(&testSearchQuery{}), api.Routes()[0].Input)
+}
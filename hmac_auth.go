@@ -0,0 +1,205 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HMACConfig configures HMAC request signature authentication handler.
+type HMACConfig struct {
+	// Secrets maps key IDs to shared secrets. Secret of a request is chosen
+	// by KeyIDHeader.
+	Secrets map[string][]byte
+
+	// Secret verifies requests without key ID or with unknown one.
+	// Principal of such requests has empty subject.
+	Secret []byte
+
+	// KeyIDHeader is a header key ID is read from. Default is X-Key-ID.
+	KeyIDHeader string
+
+	// SignatureHeader is a header hex-encoded signature is read from.
+	// Default is X-Signature.
+	SignatureHeader string
+
+	// TimestampHeader is a header request timestamp is read from, in Unix seconds.
+	// Default is X-Timestamp.
+	TimestampHeader string
+
+	// ReplayWindow is a maximum difference between request timestamp and current time.
+	// Signatures seen within the window are rejected. Default is 5 minutes.
+	ReplayWindow time.Duration
+
+	// MaxBodySize is a maximum size of request body in bytes. Requests with larger
+	// body get 413 Request Entity Too Large response. Default is 1 MB.
+	MaxBodySize int64
+}
+
+// Defaults of HMAC authentication.
+const (
+	defHMACKeyIDHeader     = "X-Key-ID"
+	defHMACSignatureHeader = "X-Signature"
+	defHMACTimestampHeader = "X-Timestamp"
+	defHMACReplayWindow    = 5 * time.Minute
+	defHMACMaxBodySize     = 1 << 20
+)
+
+// HMACAuth creates a handler which authenticates requests signed with HMAC-SHA256,
+// see SignHMAC for what is signed. Request body is read to verify signature
+// and restored, so it can be bound by the next handlers. On success key ID
+// of the secret signature was verified with is stored on request as principal
+// subject. Requests without valid signature,
+// with timestamp outside of replay window or with signature already seen get
+// 401 Unauthorized response. HMACAuth panics if there are no secrets.
+func HMACAuth(config HMACConfig) RouteHandler {
+	if len(config.Secrets) == 0 && len(config.Secret) == 0 {
+		panic("jo: no HMAC secrets specified")
+	}
+	if len(config.KeyIDHeader) == 0 {
+		config.KeyIDHeader = defHMACKeyIDHeader
+	}
+	if len(config.SignatureHeader) == 0 {
+		config.SignatureHeader = defHMACSignatureHeader
+	}
+	if len(config.TimestampHeader) == 0 {
+		config.TimestampHeader = defHMACTimestampHeader
+	}
+	if config.ReplayWindow <= 0 {
+		config.ReplayWindow = defHMACReplayWindow
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = defHMACMaxBodySize
+	}
+	seen := newSignatureCache()
+
+	return func(request *Request) *Response {
+		httpRequest := request.Context.Request
+		signature, err := hex.DecodeString(httpRequest.Header.Get(config.SignatureHeader))
+		timestampHeader := httpRequest.Header.Get(config.TimestampHeader)
+		if err != nil || len(signature) == 0 || len(timestampHeader) == 0 {
+			return unauthorized(ErrCodeMissingCredentials, "Missing request signature", "")
+		}
+		timestampSeconds, err := strconv.ParseInt(timestampHeader, 10, 64)
+		if err != nil {
+			return unauthorized(ErrCodeInvalidCredentials, "Invalid request timestamp", "")
+		}
+		now := time.Now()
+		timestamp := time.Unix(timestampSeconds, 0)
+		if timestamp.Before(now.Add(-config.ReplayWindow)) || timestamp.After(now.Add(config.ReplayWindow)) {
+			return unauthorized(ErrCodeExpiredCredentials, "Request timestamp is outside of allowed window", "")
+		}
+
+		keyID := httpRequest.Header.Get(config.KeyIDHeader)
+		secret, ok := config.Secrets[keyID]
+		subject := keyID
+		if !ok {
+			secret = config.Secret
+			subject = ""
+		}
+		body, err := readRequestBody(request, config.MaxBodySize)
+		if errors.Is(err, errBodyTooLarge) {
+			return createHTTPErrorResponse(413, "Request body is too large")
+		}
+		if err != nil {
+			return BadRequestMessage("Couldn't read request body")
+		}
+		expected := computeHMAC(
+			secret, keyID, httpRequest.Method, httpRequest.URL.RequestURI(), timestampSeconds, body)
+		if len(secret) == 0 || !hmac.Equal(expected, signature) {
+			return unauthorized(ErrCodeInvalidSignature, "Invalid request signature", "")
+		}
+		if !seen.add(hex.EncodeToString(signature), timestamp.Add(config.ReplayWindow), now) {
+			return unauthorized(ErrCodeReplayedRequest, "Request was already received", "")
+		}
+		request.SetPrincipal(&Principal{Subject: subject})
+		return Next(nil)
+	}
+}
+
+// SignHMAC returns hex-encoded HMAC-SHA256 signature of request with specified
+// key ID, method, path with query string, timestamp and body. Signed string is
+//
+//	key ID + "\n" + METHOD + "\n" + path + "\n" + Unix timestamp + "\n" + hex SHA-256 of body
+//
+// Clients send it in X-Signature header along with key ID in X-Key-ID header
+// and timestamp in X-Timestamp header. Key ID is empty when it isn't sent.
+func SignHMAC(
+	secret []byte, keyID string, method string, path string, timestamp time.Time, body []byte) string {
+	return hex.EncodeToString(computeHMAC(secret, keyID, method, path, timestamp.Unix(), body))
+}
+
+func computeHMAC(
+	secret []byte, keyID string, method string, path string, timestamp int64, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, secret)
+	io.WriteString(mac, keyID+"\n"+method+"\n"+path+"\n"+strconv.FormatInt(timestamp, 10)+"\n")
+	io.WriteString(mac, hex.EncodeToString(bodyHash[:]))
+	return mac.Sum(nil)
+}
+
+// errBodyTooLarge is returned by readRequestBody when body exceeds the limit.
+var errBodyTooLarge = errors.New("Request body is too large")
+
+// readRequestBody reads request body no longer than maxSize bytes and replaces
+// it with a reader of the same bytes.
+func readRequestBody(request *Request, maxSize int64) ([]byte, error) {
+	httpRequest := request.Context.Request
+	if httpRequest.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(httpRequest.Body, maxSize+1))
+	httpRequest.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxSize {
+		return nil, errBodyTooLarge
+	}
+	httpRequest.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// signatureCache keeps signatures until they expire to detect replayed requests.
+type signatureCache struct {
+	mutex      sync.Mutex
+	signatures map[string]time.Time
+	adds       int
+}
+
+// signatureCacheCleanupInterval is a number of added signatures after which
+// expired signatures are removed.
+const signatureCacheCleanupInterval = 100
+
+func newSignatureCache() *signatureCache {
+	return &signatureCache{signatures: make(map[string]time.Time)}
+}
+
+// add stores signature until it expires. Returns false if signature is already stored.
+func (cache *signatureCache) add(signature string, expires time.Time, now time.Time) bool {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if storedExpires, exists := cache.signatures[signature]; exists && !now.After(storedExpires) {
+		return false
+	}
+	cache.adds++
+	if cache.adds%signatureCacheCleanupInterval == 0 {
+		for stored, storedExpires := range cache.signatures {
+			if now.After(storedExpires) {
+				delete(cache.signatures, stored)
+			}
+		}
+	}
+	cache.signatures[signature] = expires
+	return true
+}
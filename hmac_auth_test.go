@@ -0,0 +1,106 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testHMACSecret = []byte("webhook-secret")
+
+// Creates headers of request signed with specified key.
+func signatureHeaders(keyID string, timestamp time.Time, signature string) map[string]string {
+	return map[string]string{
+		"X-Key-ID":    keyID,
+		"X-Timestamp": strconv.FormatInt(timestamp.Unix(), 10),
+		"X-Signature": signature,
+	}
+}
+
+// Verifies signed requests and checks body is still available to handlers.
+func TestHMACAuth(t *testing.T) {
+	api, _, _ := newAPITest()
+	auth := HMACAuth(HMACConfig{Secrets: map[string][]byte{"billing": testHMACSecret}})
+	api.Map("post", "/webhooks", auth, func(request *Request) *Response {
+		var event struct {
+			Type string `json:"type"`
+		}
+		if err := request.Bind(&event); err != nil {
+			return BadRequestError(err)
+		}
+		assert.Equal(t, "billing", request.Principal().Subject)
+		return Ok(event.Type)
+	})
+
+	body := `{"type":"invoice.paid"}`
+	now := time.Now()
+	signature := SignHMAC(testHMACSecret, "billing", "POST", "/webhooks?attempt=1", now, []byte(body))
+	recorder := recordRequest(api, "POST", "/webhooks?attempt=1", json.RawMessage(body), signatureHeaders("billing", now, signature))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invoice.paid")
+
+	// Same request can't be replayed.
+	recorder = recordRequest(api, "POST", "/webhooks?attempt=1", json.RawMessage(body), signatureHeaders("billing", now, signature))
+	assert.Equal(t, 401, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), strconv.Itoa(ErrCodeReplayedRequest))
+
+	// Signature covers path, body and timestamp.
+	recorder = recordRequest(api, "POST", "/webhooks?attempt=2", json.RawMessage(body), signatureHeaders("billing", now, signature))
+	assert.Contains(t, recorder.Body.String(), strconv.Itoa(ErrCodeInvalidSignature))
+	recorder = recordRequest(api, "POST", "/webhooks?attempt=1", json.RawMessage(`{"type":"invoice.void"}`), signatureHeaders("billing", now, signature))
+	assert.Contains(t, recorder.Body.String(), strconv.Itoa(ErrCodeInvalidSignature))
+	recorder = recordRequest(api, "POST", "/webhooks?attempt=1", json.RawMessage(body), signatureHeaders("billing", now.Add(time.Second), signature))
+	assert.Contains(t, recorder.Body.String(), strconv.Itoa(ErrCodeInvalidSignature))
+
+	old := now.Add(-10 * time.Minute)
+	signature = SignHMAC(testHMACSecret, "billing", "POST", "/webhooks", old, []byte(body))
+	recorder = recordRequest(api, "POST", "/webhooks", json.RawMessage(body), signatureHeaders("billing", old, signature))
+	assert.Equal(t, 401, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), strconv.Itoa(ErrCodeExpiredCredentials))
+
+	recorder = recordRequest(api, "POST", "/webhooks", json.RawMessage(body), signatureHeaders("billing", now, ""))
+	assert.Contains(t, recorder.Body.String(), strconv.Itoa(ErrCodeMissingCredentials))
+}
+
+// Verifies requests signed with fallback secret. Key ID is signed and isn't
+// trusted as principal subject when it doesn't resolve to a secret.
+func TestHMACAuthFallbackSecret(t *testing.T) {
+	api, _, _ := newAPITest()
+	fallbackSecret := []byte("fallback-secret")
+	auth := HMACAuth(HMACConfig{
+		Secrets: map[string][]byte{"billing": testHMACSecret}, Secret: fallbackSecret, MaxBodySize: 32})
+	api.Map("post", "/webhooks", auth, func(request *Request) *Response {
+		return Ok(request.Principal().Subject)
+	})
+
+	body := `{"type":"invoice.paid"}`
+	now := time.Now()
+	signature := SignHMAC(fallbackSecret, "admin", "POST", "/webhooks", now, []byte(body))
+	recorder := recordRequest(api, "POST", "/webhooks", json.RawMessage(body), signatureHeaders("admin", now, signature))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"data":""`)
+
+	signature = SignHMAC(fallbackSecret, "", "POST", "/webhooks", now, []byte(body))
+	recorder = recordRequest(api, "POST", "/webhooks", json.RawMessage(body), signatureHeaders("admin", now, signature))
+	assert.Equal(t, 401, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), strconv.Itoa(ErrCodeInvalidSignature))
+
+	body = `{"type":"invoice.paid","note":"too long"}`
+	signature = SignHMAC(testHMACSecret, "billing", "POST", "/webhooks", now, []byte(body))
+	recorder = recordRequest(api, "POST", "/webhooks", json.RawMessage(body), signatureHeaders("billing", now, signature))
+	assert.Equal(t, 413, recorder.Code)
+}
+
+// Checks handler can't be created without secrets.
+func TestHMACAuthInvalidConfig(t *testing.T) {
+	assert.PanicsWithValue(t, "jo: no HMAC secrets specified", func() {
+		HMACAuth(HMACConfig{})
+	})
+}
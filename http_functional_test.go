@@ -36,6 +36,19 @@ func (ht *HTTPFunctionalTest) Delete(url string) *Response {
 	return ht.callAPI("DELETE", url, nil)
 }
 
+// Head sends HEAD HTTP request to api endpoint and returns
+// wrapped response for further testing. Response contains only HTTP code
+// because HEAD responses have no body.
+func (ht *HTTPFunctionalTest) Head(url string) *Response {
+	return ht.callAPI("HEAD", url, nil)
+}
+
+// Options sends OPTIONS HTTP request to api endpoint and returns
+// wrapped response for further testing.
+func (ht *HTTPFunctionalTest) Options(url string) *Response {
+	return ht.callAPI("OPTIONS", url, nil)
+}
+
 // Post sends POST HTTP request to api endpoint and returns
 // wrapped response for further testing.
 func (ht *HTTPFunctionalTest) Post(url string, requestJSON interface{}) *Response {
@@ -54,6 +67,13 @@ func (ht *HTTPFunctionalTest) Patch(url string, requestJSON interface{}) *Respon
 	return ht.callAPI("PATCH", url, requestJSON)
 }
 
+// Send sends HTTP request with specified headers to api endpoint and returns
+// wrapped response for further testing.
+func (ht *HTTPFunctionalTest) Send(
+	method string, url string, requestJSON interface{}, headers map[string]string) *Response {
+	return ht.readResponse(recordRequest(ht.api, method, url, requestJSON, headers))
+}
+
 func (ht *HTTPFunctionalTest) callAPI(
 	method string, url string, requestJSON interface{}) *Response {
 	request := createHTTPTestRequest(method, url, requestJSON)
@@ -80,10 +100,14 @@ func jsonToBytes(requestJSON interface{}) []byte {
 func (ht *HTTPFunctionalTest) readResponse(recorder *httptest.ResponseRecorder) *Response {
 	response := &Response{}
 	responseStr := recorder.Body.String()
+	response.HTTPCode = recorder.Code
+	response.Headers = recorder.Header()
+	if len(responseStr) == 0 {
+		return response
+	}
 	err := json.Unmarshal([]byte(responseStr), &response)
 	if nil != err {
 		log.Fatalf("Couldn't parse response: %s", responseStr)
 	}
-	response.HTTPCode = recorder.Code
 	return response
 }
@@ -32,7 +32,9 @@ func NewHTTPIntegrationTest(host string) *HTTPIntegrationTest {
 }
 
 // NewHTTPIntegrationTestTLS creates new instance of HTTP testing framework with TLS
-// configuration, allowing to test over HTTPS.
+// configuration, allowing to test over HTTPS. Specified certificate is also
+// presented as client certificate, so APIs requiring client certificates can be
+// tested too. Server certificate isn't verified.
 func NewHTTPIntegrationTestTLS(host, crt, key string) *HTTPIntegrationTest {
 	cert, err := tls.LoadX509KeyPair(crt, key)
 	if err != nil {
@@ -77,6 +79,19 @@ func (ht *HTTPIntegrationTest) Delete(url string) *Response {
 	return ht.callAPI("DELETE", url, nil)
 }
 
+// Head sends HEAD HTTP request to api endpoint and returns
+// wrapped response for further testing. Response contains only HTTP code
+// because HEAD responses have no body.
+func (ht *HTTPIntegrationTest) Head(url string) *Response {
+	return ht.callAPI("HEAD", url, nil)
+}
+
+// Options sends OPTIONS HTTP request to api endpoint and returns
+// wrapped response for further testing.
+func (ht *HTTPIntegrationTest) Options(url string) *Response {
+	return ht.callAPI("OPTIONS", url, nil)
+}
+
 // Post sends POST HTTP request to api endpoint and returns
 // wrapped response for further testing.
 func (ht *HTTPIntegrationTest) Post(url string, requestJSON interface{}) *Response {
@@ -118,11 +133,15 @@ func (ht *HTTPIntegrationTest) readResponse(httpResponse *http.Response) *Respon
 		panic(err)
 	}
 	response := &Response{}
+	response.HTTPCode = httpResponse.StatusCode
+	response.Headers = httpResponse.Header
+	if len(body) == 0 {
+		return response
+	}
 	err = json.Unmarshal(body, &response)
 	if err != nil {
 		log.Fatalf("Couldn't parse response: %s", body)
 	}
-	response.HTTPCode = httpResponse.StatusCode
 	return response
 }
 
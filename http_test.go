@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"log"
 	"net/http"
+	"net/http/httptest"
 )
 
 // HTTPTest is a definition of HTTP testing API.
 type HTTPTest interface {
 	Get(url string) *Response
 	Delete(url string) *Response
+	Head(url string) *Response
+	Options(url string) *Response
 	Post(url string, requestJSON interface{}) *Response
 	Put(url string, requestJSON interface{}) *Response
 	Patch(url string, requestJSON interface{}) *Response
@@ -26,3 +29,16 @@ func createHTTPTestRequest(
 	request.Header.Add("Content-Type", "application/json")
 	return request
 }
+
+// recordRequest sends request with JSON body and specified headers to API
+// and returns raw recorded response.
+func recordRequest(api *API, method string, url string,
+	requestJSON interface{}, headers map[string]string) *httptest.ResponseRecorder {
+	request := createHTTPTestRequest(method, url, requestJSON)
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	recorder := httptest.NewRecorder()
+	api.buildEngine().ServeHTTP(recorder, request)
+	return recorder
+}
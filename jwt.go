@@ -0,0 +1,338 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// JWTConfig configures JWT authentication handler.
+type JWTConfig struct {
+	// Keys maps key IDs to verification keys. Key of a token is chosen by its
+	// "kid" header. Supported keys are []byte for HS256, *rsa.PublicKey for RS256
+	// and *ecdsa.PublicKey on P-256 curve for ES256.
+	Keys map[string]interface{}
+
+	// Key verifies tokens without "kid" header or with unknown one.
+	Key interface{}
+
+	// JWKSFile is a path to JSON Web Key Set file which keys are added to Keys.
+	JWKSFile string
+
+	// Issuer is a required "iss" claim. It isn't checked when empty.
+	Issuer string
+
+	// Audience is a value required in "aud" claim. It isn't checked when empty.
+	Audience string
+
+	// Leeway is a clock skew allowed when "exp" and "nbf" claims are checked.
+	Leeway time.Duration
+
+	// ScopeClaim is a claim principal scopes are read from, either space-separated
+	// string or array. Default is "scope".
+	ScopeClaim string
+
+	// RolesClaim is a claim principal roles are read from. Default is "roles".
+	RolesClaim string
+}
+
+// jwtHeader is a JOSE header of a token.
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// jwtError is an error of token verification.
+type jwtError struct {
+	code    int
+	message string
+}
+
+func (err *jwtError) Error() string {
+	return err.message
+}
+
+// JWTAuth creates a handler which authenticates requests with JWT bearer tokens
+// from Authorization header. HS256, RS256 and ES256 tokens are supported.
+// On success token claims are stored on request as Principal, its subject is
+// taken from "sub" claim. Requests without valid token get 401 Unauthorized
+// response, tokens issued by another issuer or for another audience get
+// 403 Forbidden response. See ErrCode constants for error codes.
+// JWTAuth panics if JWKS file can't be loaded or there are no keys.
+func JWTAuth(config JWTConfig) RouteHandler {
+	keys := make(map[string]interface{})
+	for keyID, key := range config.Keys {
+		keys[keyID] = key
+	}
+	if len(config.JWKSFile) > 0 {
+		jwksKeys, err := LoadJWKSFile(config.JWKSFile)
+		if err != nil {
+			panic(fmt.Sprintf("jo: couldn't load JWKS file: %v", err))
+		}
+		for keyID, key := range jwksKeys {
+			keys[keyID] = key
+		}
+	}
+	if len(keys) == 0 && config.Key == nil {
+		panic("jo: no JWT verification keys specified")
+	}
+	config.Keys = keys
+	if len(config.ScopeClaim) == 0 {
+		config.ScopeClaim = "scope"
+	}
+	if len(config.RolesClaim) == 0 {
+		config.RolesClaim = "roles"
+	}
+
+	return func(request *Request) *Response {
+		token, ok := bearerToken(request)
+		if !ok {
+			return unauthorized(ErrCodeMissingCredentials, "Missing bearer token", "Bearer")
+		}
+		claims, err := config.verify(token, time.Now())
+		if err != nil {
+			jwtErr := err.(*jwtError)
+			if jwtErr.code == ErrCodeInvalidIssuer || jwtErr.code == ErrCodeInvalidAudience {
+				return forbidden(jwtErr.code, jwtErr.message)
+			}
+			challenge := fmt.Sprintf("Bearer error=\"invalid_token\", error_description=%q", jwtErr.message)
+			return unauthorized(jwtErr.code, jwtErr.message, challenge)
+		}
+		request.SetPrincipal(&Principal{
+			Subject: claimString(claims["sub"]),
+			Scopes:  claimStrings(claims[config.ScopeClaim]),
+			Roles:   claimStrings(claims[config.RolesClaim]),
+			Claims:  claims,
+		})
+		return Next(nil)
+	}
+}
+
+// bearerToken returns token from Authorization header.
+func bearerToken(request *Request) (string, bool) {
+	authorization := request.Context.Request.Header.Get("Authorization")
+	const prefix = "bearer "
+	if len(authorization) <= len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(authorization[len(prefix):]), true
+}
+
+// verify checks token signature and claims and returns the claims.
+func (config *JWTConfig) verify(token string, now time.Time) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, &jwtError{ErrCodeInvalidCredentials, "Malformed token"}
+	}
+	var header jwtHeader
+	var claims map[string]interface{}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil ||
+		decodeJWTSegment(parts[0], &header) != nil ||
+		decodeJWTSegment(parts[1], &claims) != nil {
+		return nil, &jwtError{ErrCodeInvalidCredentials, "Malformed token"}
+	}
+
+	key, ok := config.Keys[header.KeyID]
+	if !ok {
+		key = config.Key
+	}
+	if key == nil {
+		return nil, &jwtError{ErrCodeInvalidSignature, "Unknown signing key"}
+	}
+	signingInput := parts[0] + "." + parts[1]
+	if err := verifyJWTSignature(header.Algorithm, key, signingInput, signature); err != nil {
+		return nil, &jwtError{ErrCodeInvalidSignature, err.Error()}
+	}
+
+	exp, expOk := numericClaim(claims, "exp")
+	nbf, nbfOk := numericClaim(claims, "nbf")
+	if !expOk || !nbfOk {
+		return nil, &jwtError{ErrCodeInvalidCredentials, "Malformed token"}
+	}
+	if exp != nil && !now.Before(unixTime(*exp).Add(config.Leeway)) {
+		return nil, &jwtError{ErrCodeExpiredCredentials, "Token is expired"}
+	}
+	if nbf != nil && now.Before(unixTime(*nbf).Add(-config.Leeway)) {
+		return nil, &jwtError{ErrCodeCredentialsNotYetValid, "Token is not valid yet"}
+	}
+	if len(config.Issuer) > 0 && claimString(claims["iss"]) != config.Issuer {
+		return nil, &jwtError{ErrCodeInvalidIssuer, "Invalid token issuer"}
+	}
+	if len(config.Audience) > 0 && !containsString(claimStrings(claims["aud"]), config.Audience) {
+		return nil, &jwtError{ErrCodeInvalidAudience, "Invalid token audience"}
+	}
+	return claims, nil
+}
+
+// numericClaim returns value of numeric date claim or nil if there's no such claim.
+// Returns false when claim isn't a number.
+func numericClaim(claims map[string]interface{}, name string) (*float64, bool) {
+	value, exists := claims[name]
+	if !exists {
+		return nil, true
+	}
+	number, ok := value.(float64)
+	return &number, ok
+}
+
+func decodeJWTSegment(segment string, value interface{}) error {
+	jsonBytes, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonBytes, value)
+}
+
+// verifyJWTSignature verifies signature with key which must match the algorithm.
+func verifyJWTSignature(algorithm string, key interface{}, signingInput string, signature []byte) error {
+	hash := sha256.Sum256([]byte(signingInput))
+	invalid := errors.New("Invalid token signature")
+	switch algorithm {
+	case "HS256":
+		secret, ok := key.([]byte)
+		if !ok {
+			return invalid
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return invalid
+		}
+	case "RS256":
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok || rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hash[:], signature) != nil {
+			return invalid
+		}
+	case "ES256":
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || publicKey.Curve != elliptic.P256() || len(signature) != 64 {
+			return invalid
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(publicKey, hash[:], r, s) {
+			return invalid
+		}
+	default:
+		return fmt.Errorf("Unsupported token algorithm %q", algorithm)
+	}
+	return nil
+}
+
+func unixTime(seconds float64) time.Time {
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*float64(time.Second)))
+}
+
+func claimString(claim interface{}) string {
+	value, _ := claim.(string)
+	return value
+}
+
+// claimStrings converts array claim or space-separated string claim to strings.
+func claimStrings(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		var values []string
+		for _, item := range value {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// jsonWebKey is a key of JSON Web Key Set.
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	Curve   string `json:"crv"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+	K       string `json:"k"`
+}
+
+// LoadJWKSFile reads JSON Web Key Set file and returns its keys by key ID.
+// RSA, P-256 EC and symmetric keys are supported, keys meant for
+// encryption are skipped.
+func LoadJWKSFile(path string) (map[string]interface{}, error) {
+	jsonBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(jsonBytes, &keySet); err != nil {
+		return nil, fmt.Errorf("Invalid JWKS file %s: %v", path, err)
+	}
+	keys := make(map[string]interface{})
+	for _, webKey := range keySet.Keys {
+		if webKey.Use == "enc" {
+			continue
+		}
+		key, err := webKey.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("Invalid key %q in JWKS file %s: %v", webKey.KeyID, path, err)
+		}
+		keys[webKey.KeyID] = key
+	}
+	return keys, nil
+}
+
+func (webKey *jsonWebKey) publicKey() (interface{}, error) {
+	switch webKey.KeyType {
+	case "RSA":
+		n, errN := decodeBigInt(webKey.N)
+		e, errE := decodeBigInt(webKey.E)
+		if errN != nil || errE != nil || !e.IsInt64() {
+			return nil, errors.New("invalid RSA parameters")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if webKey.Curve != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", webKey.Curve)
+		}
+		x, errX := decodeBigInt(webKey.X)
+		y, errY := decodeBigInt(webKey.Y)
+		if errX != nil || errY != nil {
+			return nil, errors.New("invalid EC parameters")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(webKey.K)
+	}
+	return nil, fmt.Errorf("unsupported key type %q", webKey.KeyType)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(bytes) == 0 {
+		return nil, errors.New("invalid number")
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
@@ -0,0 +1,177 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testJWTSecret = []byte("test-secret")
+
+// Creates signed token with specified algorithm, key and claims.
+func signTestToken(algorithm string, keyID string, key interface{}, claims map[string]interface{}) string {
+	header := map[string]interface{}{"alg": algorithm, "typ": "JWT"}
+	if len(keyID) > 0 {
+		header["kid"] = keyID
+	}
+	encode := func(value interface{}) string {
+		jsonBytes, _ := json.Marshal(value)
+		return base64.RawURLEncoding.EncodeToString(jsonBytes)
+	}
+	signingInput := encode(header) + "." + encode(claims)
+	hash := sha256.Sum256([]byte(signingInput))
+	var signature []byte
+	switch algorithm {
+	case "HS256":
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case "RS256":
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, hash[:])
+	case "ES256":
+		r, s, _ := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), hash[:])
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// Creates Authorization header with bearer token.
+func bearerHeader(token string) map[string]string {
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+// Authenticates request with HS256 token and checks principal is stored on request.
+func TestJWTAuth(t *testing.T) {
+	api, _, http := newAPITest()
+	auth := JWTAuth(JWTConfig{Key: testJWTSecret, Issuer: "jo", Audience: "orders"})
+	api.Map("get", "/me", auth, func(request *Request) *Response {
+		principal := request.Principal()
+		assert.Equal(t, []string{"orders:read", "orders:write"}, principal.Scopes)
+		assert.Equal(t, []string{"admin"}, principal.Roles)
+		assert.Equal(t, "jo", principal.Claims["iss"])
+		return Ok(principal.Subject)
+	})
+
+	claims := map[string]interface{}{
+		"sub":   "alice",
+		"iss":   "jo",
+		"aud":   []string{"orders", "users"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "orders:read orders:write",
+		"roles": []string{"admin"},
+	}
+	response := http.Send("GET", "/me", nil, bearerHeader(signTestToken("HS256", "", testJWTSecret, claims)))
+	AssertOk(t, response)
+	assert.Equal(t, "alice", response.Data)
+
+	response = http.Get("/me")
+	assert.Equal(t, 401, response.HTTPCode)
+	assert.Equal(t, ErrCodeMissingCredentials, response.Error.Code)
+	assert.Equal(t, "Bearer", response.Headers.Get("WWW-Authenticate"))
+}
+
+// Checks claims and signature failures result in responses with precise error codes.
+func TestJWTAuthFailures(t *testing.T) {
+	api, handlers, http := newAPITest()
+	config := JWTConfig{Key: testJWTSecret, Issuer: "jo", Audience: "orders", Leeway: time.Minute}
+	api.Map("get", "/me", JWTAuth(config), handlers.emptyHandler)
+
+	now := time.Now()
+	validClaims := func(name string, value interface{}) map[string]interface{} {
+		claims := map[string]interface{}{"sub": "alice", "iss": "jo", "aud": "orders"}
+		if len(name) > 0 {
+			claims[name] = value
+		}
+		return claims
+	}
+	sign := func(claims map[string]interface{}) string {
+		return signTestToken("HS256", "", testJWTSecret, claims)
+	}
+	cases := []struct {
+		token    string
+		httpCode int
+		code     int
+	}{
+		{"not-a-token", 401, ErrCodeInvalidCredentials},
+		{"a.b.c", 401, ErrCodeInvalidCredentials},
+		{signTestToken("HS256", "", []byte("other-secret"), validClaims("", nil)), 401, ErrCodeInvalidSignature},
+		{signTestToken("none", "", testJWTSecret, validClaims("", nil)), 401, ErrCodeInvalidSignature},
+		{sign(validClaims("exp", now.Add(-2*time.Minute).Unix())), 401, ErrCodeExpiredCredentials},
+		{sign(validClaims("nbf", now.Add(2*time.Minute).Unix())), 401, ErrCodeCredentialsNotYetValid},
+		{sign(validClaims("iss", "other")), 403, ErrCodeInvalidIssuer},
+		{sign(validClaims("aud", "users")), 403, ErrCodeInvalidAudience},
+		{sign(validClaims("exp", "1")), 401, ErrCodeInvalidCredentials},
+		{sign(validClaims("nbf", "1")), 401, ErrCodeInvalidCredentials},
+	}
+	for _, testCase := range cases {
+		response := http.Send("GET", "/me", nil, bearerHeader(testCase.token))
+		assert.Equal(t, testCase.httpCode, response.HTTPCode, testCase.token)
+		assert.Equal(t, testCase.code, response.Error.Code, testCase.token)
+	}
+
+	// Expiration within leeway is accepted.
+	AssertOk(t, http.Send("GET", "/me", nil, bearerHeader(sign(validClaims("exp", now.Add(-30*time.Second).Unix())))))
+}
+
+// Verifies RS256 and ES256 tokens with keys loaded from JWKS file.
+func TestJWTAuthJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	encode := func(value *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(value.Bytes())
+	}
+	keySet := map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa-1", "n": encode(rsaKey.N), "e": encode(big.NewInt(int64(rsaKey.E)))},
+			{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": encode(ecKey.X), "y": encode(ecKey.Y)},
+		},
+	}
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	jwksBytes, _ := json.Marshal(keySet)
+	assert.NoError(t, os.WriteFile(jwksFile, jwksBytes, 0600))
+
+	api, handlers, http := newAPITest()
+	api.Map("get", "/me", JWTAuth(JWTConfig{JWKSFile: jwksFile}), handlers.emptyHandler)
+
+	claims := map[string]interface{}{"sub": "alice"}
+	AssertOk(t, http.Send("GET", "/me", nil, bearerHeader(signTestToken("RS256", "rsa-1", rsaKey, claims))))
+	AssertOk(t, http.Send("GET", "/me", nil, bearerHeader(signTestToken("ES256", "ec-1", ecKey, claims))))
+
+	// Key of another algorithm is rejected.
+	response := http.Send("GET", "/me", nil, bearerHeader(signTestToken("ES256", "rsa-1", ecKey, claims)))
+	assert.Equal(t, ErrCodeInvalidSignature, response.Error.Code)
+	response = http.Send("GET", "/me", nil, bearerHeader(signTestToken("RS256", "unknown", rsaKey, claims)))
+	assert.Equal(t, ErrCodeInvalidSignature, response.Error.Code)
+}
+
+// Checks handler can't be created without keys.
+func TestJWTAuthInvalidConfig(t *testing.T) {
+	assert.PanicsWithValue(t, "jo: no JWT verification keys specified", func() {
+		JWTAuth(JWTConfig{})
+	})
+	assert.Panics(t, func() {
+		JWTAuth(JWTConfig{JWKSFile: "test_files/missing.json"})
+	})
+}
@@ -15,3 +15,42 @@ type ILogger interface {
 	Warn(format string, v ...interface{})
 	Error(format string, v ...interface{})
 }
+
+// StructuredLogger is a definition of a logger which attaches fields to messages.
+// Fields are specified as key/value pairs e.g. "user_id", 42.
+// When logger set on API implements this interface, jo logs via Log
+// and every request gets its own logger created via With.
+type StructuredLogger interface {
+	ILogger
+
+	// With returns logger which adds specified fields to every message.
+	With(fields ...interface{}) StructuredLogger
+
+	// Log logs message with specified level and fields.
+	Log(level LogLevel, message string, fields ...interface{})
+}
+
+// LogLevel is a level of log message.
+type LogLevel int
+
+// Log levels matching ILogger functions.
+const (
+	DebugLevel LogLevel = iota + 1
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+// logf logs message via logger function matching the level.
+func logf(logger ILogger, level LogLevel, format string, v ...interface{}) {
+	switch level {
+	case DebugLevel:
+		logger.Debug(format, v...)
+	case InfoLevel:
+		logger.Info(format, v...)
+	case WarnLevel:
+		logger.Warn(format, v...)
+	case ErrorLevel:
+		logger.Error(format, v...)
+	}
+}
@@ -0,0 +1,128 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParamError describes path parameter which couldn't be parsed.
+type ParamError struct {
+	Name  string
+	Value string
+	Kind  string
+}
+
+// Error returns description of invalid parameter.
+func (err *ParamError) Error() string {
+	return fmt.Sprintf("Invalid parameter %s: %q is not a valid %s", err.Name, err.Value, err.Kind)
+}
+
+// Param returns value of URL path parameter by its name e.g. "id" for path "/users/:id".
+func (request *Request) Param(name string) string {
+	return request.Context.Param(name)
+}
+
+// ParamInt returns value of URL path parameter as int.
+func (request *Request) ParamInt(name string) (int, error) {
+	value := request.Param(name)
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &ParamError{Name: name, Value: value, Kind: "int"}
+	}
+	return result, nil
+}
+
+// ParamInt64 returns value of URL path parameter as int64.
+func (request *Request) ParamInt64(name string) (int64, error) {
+	value := request.Param(name)
+	result, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, &ParamError{Name: name, Value: value, Kind: "int64"}
+	}
+	return result, nil
+}
+
+// ParamBool returns value of URL path parameter as bool.
+// Accepts 1, t, T, TRUE, true, True, 0, f, F, FALSE, false, False.
+func (request *Request) ParamBool(name string) (bool, error) {
+	value := request.Param(name)
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, &ParamError{Name: name, Value: value, Kind: "bool"}
+	}
+	return result, nil
+}
+
+// ParamUUID returns value of URL path parameter as UUID string
+// in canonical lowercase form e.g. "123e4567-e89b-12d3-a456-426655440000".
+func (request *Request) ParamUUID(name string) (string, error) {
+	value := request.Param(name)
+	if !isUUID(value) {
+		return "", &ParamError{Name: name, Value: value, Kind: "uuid"}
+	}
+	return strings.ToLower(value), nil
+}
+
+// ValidateParam creates a handler which checks URL path parameter to be of specified kind:
+// "int", "int64", "bool" or "uuid". The handler returns BadRequest response naming
+// the parameter when it's invalid and passes request to the next handler otherwise.
+// Panics on unknown kind.
+func ValidateParam(name string, kind string) RouteHandler {
+	parse := paramParser(kind)
+	return func(request *Request) *Response {
+		if err := parse(request, name); err != nil {
+			return BadRequestError(err)
+		}
+		return Next()
+	}
+}
+
+func paramParser(kind string) func(request *Request, name string) error {
+	switch kind {
+	case "int":
+		return func(request *Request, name string) error {
+			_, err := request.ParamInt(name)
+			return err
+		}
+	case "int64":
+		return func(request *Request, name string) error {
+			_, err := request.ParamInt64(name)
+			return err
+		}
+	case "bool":
+		return func(request *Request, name string) error {
+			_, err := request.ParamBool(name)
+			return err
+		}
+	case "uuid":
+		return func(request *Request, name string) error {
+			_, err := request.ParamUUID(name)
+			return err
+		}
+	}
+	panic(fmt.Sprintf("jo: unknown parameter kind %q", kind))
+}
+
+// isUUID checks whether string is UUID in 8-4-4-4-12 hex format.
+func isUUID(value string) bool {
+	groups := strings.Split(value, "-")
+	lengths := []int{8, 4, 4, 4, 12}
+	if len(groups) != len(lengths) {
+		return false
+	}
+	for i, group := range groups {
+		if len(group) != lengths[i] {
+			return false
+		}
+		if _, err := hex.DecodeString(group); err != nil {
+			return false
+		}
+	}
+	return true
+}
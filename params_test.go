@@ -0,0 +1,65 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates API with a route having every kind of parameter and returns them parsed.
+func TestTypedParams(t *testing.T) {
+	api, _, http := newAPITest()
+	api.Map("get", "/items/:int/:int64/:bool/:uuid", func(request *Request) *Response {
+		intValue, err := request.ParamInt("int")
+		assert.NoError(t, err)
+		int64Value, err := request.ParamInt64("int64")
+		assert.NoError(t, err)
+		boolValue, err := request.ParamBool("bool")
+		assert.NoError(t, err)
+		uuidValue, err := request.ParamUUID("uuid")
+		assert.NoError(t, err)
+		return Ok([]interface{}{intValue, int64Value, boolValue, uuidValue})
+	})
+
+	response := http.Get("/items/12/9000000000/true/123E4567-E89B-12D3-A456-426655440000")
+
+	AssertOk(t, response)
+	expected := []interface{}{
+		float64(12), float64(9000000000), true, "123e4567-e89b-12d3-a456-426655440000"}
+	assert.Equal(t, expected, response.Data)
+}
+
+// Creates API with a route having invalid parameters and checks errors.
+func TestInvalidParams(t *testing.T) {
+	api, _, http := newAPITest()
+	api.Map("get", "/items/:value", func(request *Request) *Response {
+		_, intErr := request.ParamInt("value")
+		_, boolErr := request.ParamBool("value")
+		_, uuidErr := request.ParamUUID("value")
+		_, missingErr := request.ParamInt64("missing")
+		for _, err := range []error{intErr, boolErr, uuidErr, missingErr} {
+			assert.IsType(t, &ParamError{}, err)
+		}
+		return BadRequestError(intErr)
+	})
+
+	response := http.Get("/items/abc")
+
+	AssertBadRequest(t, response, `Invalid parameter value: "abc" is not a valid int`)
+}
+
+// Creates API with parameter validation handler.
+func TestValidateParam(t *testing.T) {
+	api, handlers, http := newAPITest()
+	api.Map("get", "/users/:id", ValidateParam("id", "int"), handlers.emptyHandler)
+
+	AssertBadRequest(t, http.Get("/users/me"), `Invalid parameter id: "me" is not a valid int`)
+	AssertOk(t, http.Get("/users/1"))
+	assert.Panics(t, func() {
+		ValidateParam("id", "float")
+	})
+}
@@ -0,0 +1,54 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+// principalKey is a key of principal stored on request.
+const principalKey = "jo.principal"
+
+// Principal is an authenticated client of API, e.g. user or service.
+// It's stored on request by authentication handlers.
+type Principal struct {
+	// Subject identifies the client, e.g. user ID or API key owner.
+	Subject string
+
+	// Scopes are permissions granted to the client.
+	Scopes []string
+
+	// Roles are roles assigned to the client.
+	Roles []string
+
+	// Claims are all claims of the client credentials, e.g. JWT claims.
+	Claims map[string]interface{}
+}
+
+// SetPrincipal stores authenticated client on request.
+func (request *Request) SetPrincipal(principal *Principal) {
+	request.Set(principalKey, principal)
+}
+
+// Principal returns authenticated client or nil if request isn't authenticated.
+func (request *Request) Principal() *Principal {
+	principal, _ := Value[*Principal](request, principalKey)
+	return principal
+}
+
+// HasScope checks whether principal was granted specified scope.
+func (principal *Principal) HasScope(scope string) bool {
+	return containsString(principal.Scopes, scope)
+}
+
+// HasRole checks whether principal has specified role.
+func (principal *Principal) HasRole(role string) bool {
+	return containsString(principal.Roles, role)
+}
+
+func containsString(values []string, value string) bool {
+	for _, current := range values {
+		if current == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Stores principal on request and checks it's available to the next handler.
+func TestPrincipal(t *testing.T) {
+	api, _, http := newAPITest()
+	api.Map("get", "/me",
+		func(request *Request) *Response {
+			assert.Nil(t, request.Principal())
+			request.SetPrincipal(&Principal{Subject: "alice", Scopes: []string{"orders:read"}, Roles: []string{"admin"}})
+			return Next(nil)
+		},
+		func(request *Request) *Response {
+			principal := request.Principal()
+			assert.True(t, principal.HasScope("orders:read"))
+			assert.False(t, principal.HasScope("orders:write"))
+			assert.True(t, principal.HasRole("admin"))
+			return Ok(principal.Subject)
+		})
+	AssertOk(t, http.Get("/me"))
+}
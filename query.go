@@ -0,0 +1,211 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryInt returns query string value as int.
+// Default value is returned when argument is missing or empty.
+func (request *Request) QueryInt(name string, def int) (int, error) {
+	value := request.GetQuery(name)
+	if len(value) == 0 {
+		return def, nil
+	}
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return def, &ParamError{Name: name, Value: value, Kind: "int"}
+	}
+	return result, nil
+}
+
+// QueryBool returns query string value as bool.
+// Default value is returned when argument is missing or empty.
+func (request *Request) QueryBool(name string, def bool) (bool, error) {
+	value := request.GetQuery(name)
+	if len(value) == 0 {
+		return def, nil
+	}
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		return def, &ParamError{Name: name, Value: value, Kind: "bool"}
+	}
+	return result, nil
+}
+
+// QueryDuration returns query string value as duration e.g. "1h30m".
+// Default value is returned when argument is missing or empty.
+func (request *Request) QueryDuration(name string, def time.Duration) (time.Duration, error) {
+	value := request.GetQuery(name)
+	if len(value) == 0 {
+		return def, nil
+	}
+	result, err := time.ParseDuration(value)
+	if err != nil {
+		return def, &ParamError{Name: name, Value: value, Kind: "duration"}
+	}
+	return result, nil
+}
+
+// QueryTime returns query string value as time in RFC 3339 format.
+// Default value is returned when argument is missing or empty.
+func (request *Request) QueryTime(name string, def time.Time) (time.Time, error) {
+	value := request.GetQuery(name)
+	if len(value) == 0 {
+		return def, nil
+	}
+	result, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return def, &ParamError{Name: name, Value: value, Kind: "time"}
+	}
+	return result, nil
+}
+
+// QueryStrings returns list of query string values. Both repeated arguments
+// e.g. "?id=1&id=2" and comma separated lists e.g. "?id=1,2" are supported.
+// Empty items are skipped.
+func (request *Request) QueryStrings(name string) []string {
+	var result []string
+	for _, value := range request.Context.Request.URL.Query()[name] {
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if len(item) > 0 {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
+// BindQuery fills struct fields from query string and validates them
+// according to "validate" tags (see Validate).
+// Argument name is specified by "query" tag, fields without it are skipped.
+// Default value can be specified by "default" tag.
+// Supported field types are strings, numbers, bools, time.Duration, time.Time (RFC 3339)
+// and slices of them which are parsed as in QueryStrings. Values of other fields
+// are taken as is, commas included; repeated arguments fail them.
+// Returns *ValidationError listing fields which couldn't be parsed
+// with reason "type" or failed validation.
+// Output must be a pointer to struct, otherwise BindQuery panics.
+func (request *Request) BindQuery(output interface{}) error {
+	value := reflect.ValueOf(output)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("jo: BindQuery expects pointer to struct, got %T", output))
+	}
+	value = value.Elem()
+	var fieldErrors []FieldError
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := field.Tag.Get("query")
+		if len(name) == 0 || len(field.PkgPath) > 0 {
+			continue
+		}
+		fieldValue := value.Field(i)
+		var rawValues []string
+		if fieldValue.Kind() == reflect.Slice {
+			rawValues = request.QueryStrings(name)
+		} else if queryValues := request.Context.Request.URL.Query()[name]; len(queryValues) > 1 ||
+			(len(queryValues) == 1 && len(queryValues[0]) > 0) {
+			rawValues = queryValues
+		}
+		if len(rawValues) == 0 {
+			if def, ok := field.Tag.Lookup("default"); ok {
+				rawValues = []string{def}
+			}
+		}
+		if len(rawValues) == 0 {
+			continue
+		}
+		if fieldValue.Kind() != reflect.Slice && len(rawValues) > 1 {
+			fieldErrors = append(fieldErrors,
+				FieldError{Field: name, Reason: "type", Param: scalarKind(fieldValue)})
+			continue
+		}
+		if err := setFieldValue(fieldValue, rawValues); err != nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: name, Reason: "type", Param: err.Kind})
+		}
+	}
+	return validateFields(output, "query", fieldErrors)
+}
+
+// setFieldValue parses raw values into a field. Slices get every value,
+// other fields the only one.
+func setFieldValue(field reflect.Value, rawValues []string) *ParamError {
+	if field.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(field.Type(), len(rawValues), len(rawValues))
+		for i, rawValue := range rawValues {
+			if err := setScalarValue(slice.Index(i), rawValue); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setScalarValue(field, rawValues[0])
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+var timeType = reflect.TypeOf(time.Time{})
+
+// scalarKind returns name of scalar field type used in parsing errors.
+func scalarKind(field reflect.Value) string {
+	switch field.Type() {
+	case durationType:
+		return "duration"
+	case timeType:
+		return "time"
+	}
+	return field.Kind().String()
+}
+
+func setScalarValue(field reflect.Value, rawValue string) *ParamError {
+	var err error
+	kind := scalarKind(field)
+	switch {
+	case field.Type() == durationType:
+		var duration time.Duration
+		if duration, err = time.ParseDuration(rawValue); err == nil {
+			field.SetInt(int64(duration))
+		}
+	case field.Type() == timeType:
+		var parsed time.Time
+		if parsed, err = time.Parse(time.RFC3339, rawValue); err == nil {
+			field.Set(reflect.ValueOf(parsed))
+		}
+	case field.Kind() == reflect.String:
+		field.SetString(rawValue)
+	case field.Kind() == reflect.Bool:
+		var parsed bool
+		if parsed, err = strconv.ParseBool(rawValue); err == nil {
+			field.SetBool(parsed)
+		}
+	case field.Kind() >= reflect.Int && field.Kind() <= reflect.Int64:
+		var parsed int64
+		if parsed, err = strconv.ParseInt(rawValue, 10, field.Type().Bits()); err == nil {
+			field.SetInt(parsed)
+		}
+	case field.Kind() >= reflect.Uint && field.Kind() <= reflect.Uint64:
+		var parsed uint64
+		if parsed, err = strconv.ParseUint(rawValue, 10, field.Type().Bits()); err == nil {
+			field.SetUint(parsed)
+		}
+	case field.Kind() == reflect.Float32 || field.Kind() == reflect.Float64:
+		var parsed float64
+		if parsed, err = strconv.ParseFloat(rawValue, field.Type().Bits()); err == nil {
+			field.SetFloat(parsed)
+		}
+	default:
+		panic(fmt.Sprintf("jo: unsupported query field type %s", field.Type()))
+	}
+	if err != nil {
+		return &ParamError{Value: rawValue, Kind: kind}
+	}
+	return nil
+}
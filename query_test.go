@@ -0,0 +1,129 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates API with handler reading typed query arguments with defaults.
+func TestTypedQuery(t *testing.T) {
+	api, _, http := newAPITest()
+	defTime := time.Date(2016, 12, 18, 0, 0, 0, 0, time.UTC)
+	api.Map("get", "/search", func(request *Request) *Response {
+		limit, err := request.QueryInt("limit", 10)
+		assert.NoError(t, err)
+		flag, err := request.QueryBool("flag", false)
+		assert.NoError(t, err)
+		timeout, err := request.QueryDuration("timeout", time.Second)
+		assert.NoError(t, err)
+		since, err := request.QueryTime("since", defTime)
+		assert.NoError(t, err)
+		return Ok([]interface{}{limit, flag, timeout.String(), since, request.QueryStrings("id")})
+	})
+
+	response := http.Get("/search?flag=true&timeout=1m&id=1,2&id=3&id=")
+	AssertOk(t, response)
+	expected := []interface{}{
+		float64(10), true, "1m0s", "2016-12-18T00:00:00Z", []interface{}{"1", "2", "3"}}
+	assert.Equal(t, expected, response.Data)
+}
+
+// Creates API with handler reading invalid query argument.
+func TestInvalidQuery(t *testing.T) {
+	api, _, http := newAPITest()
+	api.Map("get", "/search", func(request *Request) *Response {
+		limit, err := request.QueryInt("limit", 10)
+		assert.Equal(t, 10, limit)
+		return BadRequestError(err)
+	})
+
+	response := http.Get("/search?limit=ten")
+	AssertBadRequest(t, response, `Invalid parameter limit: "ten" is not a valid int`)
+}
+
+type testSearchQuery struct {
+	Limit   int           `query:"limit" default:"20" validate:"max=100"`
+	Offset  uint          `query:"offset"`
+	Exact   bool          `query:"exact"`
+	Sort    string        `query:"sort" default:"name" validate:"enum=name|date"`
+	IDs     []int         `query:"id"`
+	Timeout time.Duration `query:"timeout"`
+	Since   time.Time     `query:"since"`
+	Skipped string
+}
+
+// Creates API with handler binding query string to struct.
+func TestBindQuery(t *testing.T) {
+	api, _, http := newAPITest()
+	api.Map("get", "/search", func(request *Request) *Response {
+		query := &testSearchQuery{}
+		if err := request.BindQuery(query); err != nil {
+			return BadRequestError(err)
+		}
+		return Ok(query)
+	})
+
+	response := http.Get("/search?offset=5&exact=1&id=1,2&timeout=2s&since=2016-12-18T00:00:00Z")
+	AssertOk(t, response)
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, float64(20), data["Limit"])
+	assert.Equal(t, float64(5), data["Offset"])
+	assert.Equal(t, true, data["Exact"])
+	assert.Equal(t, "name", data["Sort"])
+	assert.Equal(t, []interface{}{float64(1), float64(2)}, data["IDs"])
+	assert.Equal(t, float64(2*time.Second), data["Timeout"])
+
+	response = http.Get("/search?limit=1000&offset=-1&id=a&sort=size")
+	AssertBadRequest(t, response, "Validation failed")
+	fields := response.Error.Data.([]interface{})
+	assert.Equal(t, 4, len(fields))
+	assert.Equal(t,
+		map[string]interface{}{"field": "offset", "reason": "type", "param": "uint"}, fields[0])
+	assert.Equal(t,
+		map[string]interface{}{"field": "id", "reason": "type", "param": "int"}, fields[1])
+	assert.Equal(t,
+		map[string]interface{}{"field": "limit", "reason": "max", "param": "100"}, fields[2])
+	assert.Equal(t,
+		map[string]interface{}{"field": "sort", "reason": "enum", "param": "name|date"}, fields[3])
+}
+
+// Creates API binding query string with commas in scalar and slice fields.
+// Only slices must be split, repeated scalar arguments must fail.
+func TestBindQueryCommas(t *testing.T) {
+	api, _, http := newAPITest()
+	api.Map("get", "/search", func(request *Request) *Response {
+		query := &struct {
+			Q    string   `query:"q"`
+			N    int      `query:"n"`
+			Tags []string `query:"tag"`
+		}{}
+		if err := request.BindQuery(query); err != nil {
+			return BadRequestError(err)
+		}
+		return Ok(query)
+	})
+
+	response := http.Get("/search?q=hello,%20world&tag=a,b&tag=c")
+	AssertOk(t, response)
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, "hello, world", data["Q"])
+	assert.Equal(t, []interface{}{"a", "b", "c"}, data["Tags"])
+
+	response = http.Get("/search?n=1,2")
+	AssertBadRequest(t, response, "Validation failed")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "n", "reason": "type", "param": "int"},
+	}, response.Error.Data)
+
+	response = http.Get("/search?q=a&q=b")
+	AssertBadRequest(t, response, "Validation failed")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "q", "reason": "type", "param": "string"},
+	}, response.Error.Data)
+}
@@ -0,0 +1,254 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitAlgorithm is an algorithm requests are limited with.
+type RateLimitAlgorithm int
+
+const (
+	// TokenBucket allows bursts of up to Limit requests and refills
+	// Limit tokens evenly during Window.
+	TokenBucket RateLimitAlgorithm = iota + 1
+
+	// SlidingWindow allows up to Limit requests during any Window, estimating
+	// requests of the sliding window from counters of current and previous fixed windows.
+	SlidingWindow
+)
+
+// RateLimitKeyFunc returns a key requests are limited by, e.g. client IP.
+type RateLimitKeyFunc func(request *Request) string
+
+// RateLimitConfig configures rate limiting handler.
+type RateLimitConfig struct {
+	// Algorithm is TokenBucket by default.
+	Algorithm RateLimitAlgorithm
+
+	// Limit is a number of requests allowed during Window.
+	Limit int
+
+	// Window is a duration Limit applies to.
+	Window time.Duration
+
+	// Key returns a key requests are limited by. Default is KeyByIP.
+	Key RateLimitKeyFunc
+
+	// Store keeps limit state of every key. By default every handler
+	// has its own in-memory store.
+	Store RateLimitStore
+}
+
+// RateLimitState is a limit state of a key. Meaning of the fields depends on
+// algorithm, stores should keep them as is.
+type RateLimitState struct {
+	// Count is a number of tokens left in bucket or number of requests
+	// in current window.
+	Count float64
+
+	// PrevCount is a number of requests in previous window.
+	PrevCount float64
+
+	// Timestamp is a time of the last refill or start of current window.
+	Timestamp time.Time
+}
+
+// RateLimitStore is a definition of an object which keeps limit states of keys.
+// Implement it to share limits between API instances, e.g. via Redis.
+type RateLimitStore interface {
+	// Update atomically replaces state of the key with the one returned by update
+	// and keeps it for at least ttl. Update is called with zero state for unknown
+	// or expired keys and may be called more than once, e.g. on write conflicts.
+	Update(key string, ttl time.Duration, update func(state RateLimitState) RateLimitState) error
+}
+
+// rateLimitResult is a result of taking a request from the limit.
+type rateLimitResult struct {
+	allowed    bool
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+// RateLimit creates a handler which limits number of requests per key.
+// Requests exceeding the limit get 429 Too Many Requests response with Retry-After
+// header. RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers
+// are set on every response. RateLimit panics if limit or window isn't positive.
+func RateLimit(config RateLimitConfig) RouteHandler {
+	if config.Limit <= 0 || config.Window <= 0 {
+		panic("jo: rate limit and window must be positive")
+	}
+	if config.Key == nil {
+		config.Key = KeyByIP
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryRateLimitStore()
+	}
+	take := takeToken
+	if config.Algorithm == SlidingWindow {
+		take = takeFromWindow
+	}
+	return func(request *Request) *Response {
+		var result rateLimitResult
+		now := time.Now()
+		err := config.Store.Update(config.Key(request), 2*config.Window,
+			func(state RateLimitState) RateLimitState {
+				state, result = take(state, config.Limit, config.Window, now)
+				return state
+			})
+		if err != nil {
+			return Error(err)
+		}
+		response := Next(nil)
+		if !result.allowed {
+			response = TooManyRequests(result.retryAfter)
+		}
+		response.WithHeader("RateLimit-Limit", strconv.Itoa(config.Limit))
+		response.WithHeader("RateLimit-Remaining", strconv.Itoa(result.remaining))
+		response.WithHeader("RateLimit-Reset", strconv.FormatInt(ceilSeconds(result.reset), 10))
+		return response
+	}
+}
+
+// KeyByIP limits requests by client IP.
+func KeyByIP(request *Request) string {
+	return request.Context.ClientIP()
+}
+
+// KeyByHeader limits requests by value of specified header, e.g. API key.
+func KeyByHeader(name string) RateLimitKeyFunc {
+	return func(request *Request) string {
+		return request.Context.Request.Header.Get(name)
+	}
+}
+
+// KeyByQuery limits requests by value of specified query parameter.
+func KeyByQuery(name string) RateLimitKeyFunc {
+	return func(request *Request) string {
+		return request.Context.Query(name)
+	}
+}
+
+// takeToken takes a token from the bucket refilling it since the last request.
+func takeToken(
+	state RateLimitState, limit int, window time.Duration, now time.Time) (RateLimitState, rateLimitResult) {
+	capacity := float64(limit)
+	// Duration in which given number of tokens is refilled.
+	refillTime := func(tokens float64) time.Duration {
+		return time.Duration(tokens * float64(window) / capacity)
+	}
+	if state.Timestamp.IsZero() {
+		state.Count = capacity
+	} else if elapsed := now.Sub(state.Timestamp); elapsed > 0 {
+		state.Count = math.Min(capacity, state.Count+float64(elapsed)*capacity/float64(window))
+	}
+	state.Timestamp = now
+
+	var result rateLimitResult
+	if state.Count >= 1 {
+		state.Count--
+		result.allowed = true
+	} else {
+		result.retryAfter = refillTime(1 - state.Count)
+	}
+	result.remaining = int(state.Count)
+	result.reset = refillTime(capacity - state.Count)
+	return state, result
+}
+
+// takeFromWindow counts request in current window if number of requests
+// in sliding window doesn't exceed the limit.
+func takeFromWindow(
+	state RateLimitState, limit int, window time.Duration, now time.Time) (RateLimitState, rateLimitResult) {
+	windowStart := now.Truncate(window)
+	if !state.Timestamp.Equal(windowStart) {
+		if state.Timestamp.Equal(windowStart.Add(-window)) {
+			state.PrevCount = state.Count
+		} else {
+			state.PrevCount = 0
+		}
+		state.Count = 0
+		state.Timestamp = windowStart
+	}
+
+	elapsed := now.Sub(windowStart)
+	weight := 1 - float64(elapsed)/float64(window)
+	estimated := state.PrevCount*weight + state.Count
+	var result rateLimitResult
+	result.reset = window - elapsed
+	if estimated+1 <= float64(limit) {
+		state.Count++
+		estimated++
+		result.allowed = true
+	} else if state.PrevCount > 0 && state.Count < float64(limit) {
+		// Previous window weight which lets one more request in.
+		allowedWeight := (float64(limit) - 1 - state.Count) / state.PrevCount
+		result.retryAfter = time.Duration((1-allowedWeight)*float64(window)) - elapsed
+	} else {
+		result.retryAfter = result.reset
+	}
+	result.remaining = int(math.Max(0, float64(limit)-math.Ceil(estimated)))
+	return state, result
+}
+
+// ceilSeconds rounds duration up to whole seconds.
+func ceilSeconds(duration time.Duration) int64 {
+	return int64(math.Ceil(duration.Seconds()))
+}
+
+// MemoryRateLimitStore keeps limit states in memory of the process.
+type MemoryRateLimitStore struct {
+	mutex   sync.Mutex
+	entries map[string]memoryRateLimitEntry
+	updates int
+}
+
+type memoryRateLimitEntry struct {
+	state   RateLimitState
+	expires time.Time
+}
+
+// memoryRateLimitCleanupInterval is a number of updates after which
+// expired entries are removed.
+const memoryRateLimitCleanupInterval = 1000
+
+// NewMemoryRateLimitStore creates empty in-memory store.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{entries: make(map[string]memoryRateLimitEntry)}
+}
+
+// Update replaces state of the key with the one returned by update.
+func (store *MemoryRateLimitStore) Update(
+	key string, ttl time.Duration, update func(state RateLimitState) RateLimitState) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := time.Now()
+	store.updates++
+	if store.updates%memoryRateLimitCleanupInterval == 0 {
+		store.removeExpired(now)
+	}
+	entry, ok := store.entries[key]
+	if !ok || now.After(entry.expires) {
+		entry = memoryRateLimitEntry{}
+	}
+	entry.state = update(entry.state)
+	entry.expires = now.Add(ttl)
+	store.entries[key] = entry
+	return nil
+}
+
+func (store *MemoryRateLimitStore) removeExpired(now time.Time) {
+	for key, entry := range store.entries {
+		if now.After(entry.expires) {
+			delete(store.entries, key)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Limits requests and checks 429 response is returned when limit is exceeded.
+func TestRateLimit(t *testing.T) {
+	api, handlers, http := newAPITest()
+	api.Map("get", "/orders", RateLimit(RateLimitConfig{Limit: 2, Window: time.Minute}), handlers.emptyHandler)
+
+	response := http.Get("/orders")
+	AssertOk(t, response)
+	assert.Equal(t, "2", response.Headers.Get("RateLimit-Limit"))
+	assert.Equal(t, "1", response.Headers.Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", response.Headers.Get("RateLimit-Reset"))
+	AssertOk(t, http.Get("/orders"))
+
+	response = http.Get("/orders")
+	AssertTooManyRequests(t, response)
+	assert.Equal(t, "30", response.Headers.Get("Retry-After"))
+	assert.Equal(t, "0", response.Headers.Get("RateLimit-Remaining"))
+}
+
+// Limits requests by header and checks keys are limited separately.
+func TestRateLimitKey(t *testing.T) {
+	api, handlers, _ := newAPITest()
+	config := RateLimitConfig{Algorithm: SlidingWindow, Limit: 1, Window: time.Hour, Key: KeyByHeader("X-API-Key")}
+	api.Map("get", "/orders", RateLimit(config), handlers.emptyHandler)
+
+	request := func(key string) int {
+		return recordRequest(api, "GET", "/orders", nil, map[string]string{"X-API-Key": key}).Code
+	}
+	assert.Equal(t, 200, request("first"))
+	assert.Equal(t, 429, request("first"))
+	assert.Equal(t, 200, request("second"))
+}
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Update(
+	key string, ttl time.Duration, update func(state RateLimitState) RateLimitState) error {
+	return errors.New("store is down")
+}
+
+// Checks store errors result in internal error response.
+func TestRateLimitStoreError(t *testing.T) {
+	api, handlers, http := newAPITest()
+	config := RateLimitConfig{Limit: 1, Window: time.Second, Store: failingRateLimitStore{}}
+	api.Map("get", "/orders", RateLimit(config), handlers.emptyHandler)
+	AssertResponseError(t, http.Get("/orders"), "store is down")
+}
+
+// Checks invalid configuration panics.
+func TestRateLimitInvalidConfig(t *testing.T) {
+	assert.PanicsWithValue(t, "jo: rate limit and window must be positive", func() {
+		RateLimit(RateLimitConfig{Limit: 0, Window: time.Second})
+	})
+}
+
+// Checks tokens are taken from bucket and refilled over time.
+func TestTakeToken(t *testing.T) {
+	now := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	var state RateLimitState
+	var result rateLimitResult
+	for i := 0; i < 10; i++ {
+		state, result = takeToken(state, 10, 10*time.Second, now)
+		assert.True(t, result.allowed)
+	}
+	state, result = takeToken(state, 10, 10*time.Second, now)
+	assert.False(t, result.allowed)
+	assert.Equal(t, time.Second, result.retryAfter)
+	assert.Equal(t, 10*time.Second, result.reset)
+
+	state, result = takeToken(state, 10, 10*time.Second, now.Add(2500*time.Millisecond))
+	assert.True(t, result.allowed)
+	assert.Equal(t, 1, result.remaining)
+	assert.InDelta(t, 1.5, state.Count, 0.001)
+}
+
+// Checks requests of previous window are weighted in sliding window.
+func TestTakeFromWindow(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	var state RateLimitState
+	var result rateLimitResult
+	for i := 0; i < 4; i++ {
+		state, result = takeFromWindow(state, 4, time.Minute, start.Add(50*time.Second))
+		assert.True(t, result.allowed)
+	}
+	state, result = takeFromWindow(state, 4, time.Minute, start.Add(55*time.Second))
+	assert.False(t, result.allowed)
+	assert.Equal(t, 5*time.Second, result.retryAfter)
+
+	// 4 requests of previous window weigh 3 after quarter of the window.
+	state, result = takeFromWindow(state, 4, time.Minute, start.Add(75*time.Second))
+	assert.True(t, result.allowed)
+	assert.Equal(t, 0, result.remaining)
+	state, result = takeFromWindow(state, 4, time.Minute, start.Add(75*time.Second))
+	assert.False(t, result.allowed)
+	assert.Equal(t, 15*time.Second, result.retryAfter)
+
+	// Windows older than the previous one are ignored.
+	_, result = takeFromWindow(state, 4, time.Minute, start.Add(5*time.Minute))
+	assert.True(t, result.allowed)
+	assert.Equal(t, 3, result.remaining)
+}
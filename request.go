@@ -4,7 +4,20 @@
 
 package jo
 
-import "gopkg.in/gin-gonic/gin.v1"
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"gopkg.in/gin-gonic/gin.v1"
+)
+
+// ErrEmptyBody is returned by Request.Bind when request has no body or its body is JSON null.
+var ErrEmptyBody = errors.New("Request body is empty")
 
 // Request contains request specific data.
 type Request struct {
@@ -22,6 +35,38 @@ type Request struct {
 
 	// Logger is a user defined logging interface.
 	Logger ILogger
+
+	// route is a definition of the route being handled.
+	route *Route
+
+	// response is a final response written to client.
+	response *Response
+
+	// id is a unique identifier of the request.
+	id string
+
+	// ctx is a context of the request.
+	ctx context.Context
+
+	// values is a store of values shared between handlers.
+	values      map[string]interface{}
+	valuesMutex sync.RWMutex
+}
+
+// Ctx returns context of the request. It's done when client disconnects
+// or request handling timeout configured on API or route expires.
+// It should be passed to database calls and other long running operations.
+func (request *Request) Ctx() context.Context {
+	if request.ctx == nil {
+		return request.Context.Request.Context()
+	}
+	return request.ctx
+}
+
+// ID returns unique identifier of the request. It's either taken from request
+// header (X-Request-ID by default) or generated.
+func (request *Request) ID() string {
+	return request.id
 }
 
 // GetQuery returns request query string value by specified argument name.
@@ -33,3 +78,53 @@ func (request *Request) GetQuery(name string) string {
 func (request *Request) GetJSON(output interface{}) {
 	request.Context.BindJSON(output)
 }
+
+// Bind deserializes JSON request body into specified object and validates it
+// according to "validate" struct tags (see Validate).
+// Returns ErrEmptyBody if there's no body, error describing malformed JSON
+// or *ValidationError listing failed fields.
+func (request *Request) Bind(output interface{}) error {
+	if request.Context.Request.Body == nil {
+		return ErrEmptyBody
+	}
+	body, err := ioutil.ReadAll(request.Context.Request.Body)
+	if err != nil {
+		return err
+	}
+	trimmedBody := bytes.TrimSpace(body)
+	if len(trimmedBody) == 0 || bytes.Equal(trimmedBody, []byte("null")) {
+		return ErrEmptyBody
+	}
+	if err := json.Unmarshal(trimmedBody, output); err != nil {
+		return fmt.Errorf("Malformed request body: %v", err)
+	}
+	return Validate(output)
+}
+
+// clone creates a copy of request with its own store of values.
+func (request *Request) clone() *Request {
+	clone := &Request{}
+	clone.Context = request.Context
+	clone.takeOver(request)
+	clone.route = request.route
+	clone.response = request.response
+	clone.id = request.id
+	clone.ctx = request.ctx
+	return clone
+}
+
+// takeOver copies handler-visible state of other request: previous response,
+// logger, global context and stored values.
+func (request *Request) takeOver(other *Request) {
+	request.GlobalContext = other.GlobalContext
+	request.PrevHandlerResponse = other.PrevHandlerResponse
+	request.Logger = other.Logger
+	other.valuesMutex.RLock()
+	defer other.valuesMutex.RUnlock()
+	request.valuesMutex.Lock()
+	defer request.valuesMutex.Unlock()
+	request.values = make(map[string]interface{}, len(other.values))
+	for key, value := range other.values {
+		request.values[key] = value
+	}
+}
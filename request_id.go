@@ -0,0 +1,54 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"gopkg.in/gin-gonic/gin.v1"
+)
+
+// maxRequestIDLength is a maximum length of request ID accepted from client.
+const maxRequestIDLength = 128
+
+// SetRequestIDHeader sets name of HTTP header request ID is read from and
+// written to. Default is X-Request-ID.
+func (api *API) SetRequestIDHeader(name string) {
+	api.requestIDHeader = name
+}
+
+// createRequestID takes request ID from request header if it's valid
+// or generates a new one.
+func (api *API) createRequestID(innerContext *gin.Context) string {
+	requestID := innerContext.Request.Header.Get(api.requestIDHeader)
+	if isValidRequestID(requestID) {
+		return requestID
+	}
+	return generateRequestID()
+}
+
+// isValidRequestID checks whether request ID has reasonable length
+// and consists of printable ASCII characters.
+func isValidRequestID(requestID string) bool {
+	if len(requestID) == 0 || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// generateRequestID generates random 128-bit identifier in hex.
+func generateRequestID() string {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(bytes)
+}
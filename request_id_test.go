@@ -0,0 +1,73 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates API and checks request ID is generated, echoed in header
+// and included in error responses.
+func TestRequestIDGenerated(t *testing.T) {
+	api, _, http := newAPITest()
+	var handlerRequestID string
+	api.Map("get", "/fail", func(request *Request) *Response {
+		handlerRequestID = request.ID()
+		return BadRequest()
+	})
+
+	response := http.Get("/fail")
+
+	AssertBadRequest(t, response)
+	assert.Regexp(t, "^[0-9a-f]{32}$", handlerRequestID)
+	assert.Equal(t, handlerRequestID, response.Headers.Get("X-Request-ID"))
+	assert.Equal(t, handlerRequestID, response.Error.RequestID)
+	nextResponse := http.Get("/fail")
+	assert.NotEqual(t, response.Error.RequestID, nextResponse.Error.RequestID)
+}
+
+// Creates API with custom request ID header and passes valid and invalid IDs.
+func TestRequestIDFromHeader(t *testing.T) {
+	api, handlers, _ := newAPITest()
+	logger := &testLogger{}
+	api.SetLogger(logger)
+	api.SetRequestIDHeader("X-Correlation-ID")
+	api.Map("get", "/ok", handlers.emptyHandler)
+	engine := api.buildEngine()
+
+	request := createHTTPTestRequest("GET", "/ok", nil)
+	request.Header.Set("X-Correlation-ID", "abc-123")
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, request)
+	assert.Equal(t, "abc-123", recorder.Header().Get("X-Correlation-ID"))
+	assert.Contains(t, logger.messages[0], "request_id=abc-123")
+
+	request = createHTTPTestRequest("GET", "/ok", nil)
+	request.Header.Set("X-Correlation-ID", "bad id")
+	recorder = httptest.NewRecorder()
+	engine.ServeHTTP(recorder, request)
+	assert.Regexp(t, "^[0-9a-f]{32}$", recorder.Header().Get("X-Correlation-ID"))
+}
+
+// Creates API with handler returning shared failed response. Request ID must be
+// set on response rendered to client, but not on shared one.
+func TestRequestIDSharedResponse(t *testing.T) {
+	api, _, http := newAPITest()
+	errForbidden := Forbidden()
+	api.Map("get", "/fail", func(request *Request) *Response {
+		return errForbidden
+	})
+
+	first := http.Get("/fail")
+	second := http.Get("/fail")
+
+	assert.Regexp(t, "^[0-9a-f]{32}$", first.Error.RequestID)
+	assert.Regexp(t, "^[0-9a-f]{32}$", second.Error.RequestID)
+	assert.NotEqual(t, first.Error.RequestID, second.Error.RequestID)
+	assert.Empty(t, errForbidden.Error.RequestID)
+}
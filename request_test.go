@@ -0,0 +1,50 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates API with handler binding request body and checks responses
+// for empty, malformed, invalid and valid bodies.
+func TestBind(t *testing.T) {
+	api, _, http := newAPITest()
+	path := "/orders"
+	api.Map("post", path, func(request *Request) *Response {
+		order := &testOrder{}
+		if err := request.Bind(order); err != nil {
+			return BadRequestError(err)
+		}
+		return Ok(order.Name)
+	})
+
+	response := http.Post(path, nil)
+	AssertBadRequest(t, response, ErrEmptyBody.Error())
+
+	response = http.Post(path, "not an object")
+	assert.Equal(t, 400, response.HTTPCode)
+	assert.Contains(t, response.Error.Message, "Malformed request body")
+
+	invalidOrder := newValidTestOrder()
+	invalidOrder.Quantity = 0
+	invalidOrder.Address = nil
+	response = http.Post(path, invalidOrder)
+	AssertBadRequest(t, response, "Validation failed")
+	fields := response.Error.Data.([]interface{})
+	assert.Equal(t, 2, len(fields))
+	field := fields[0].(map[string]interface{})
+	assert.Equal(t, "quantity", field["field"])
+	assert.Equal(t, "min", field["reason"])
+	field = fields[1].(map[string]interface{})
+	assert.Equal(t, "address", field["field"])
+	assert.Equal(t, "required", field["reason"])
+
+	response = http.Post(path, newValidTestOrder())
+	AssertOk(t, response)
+	assert.Equal(t, "Order", response.Data)
+}
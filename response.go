@@ -4,12 +4,19 @@
 
 package jo
 
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
 // Response describes common service response format.
 // Every HTTP response is wrapped in this structure.
 // NOTE this structure isn't directly serialized to JSON anywhere except tests.
-// Instead we take required fields because error field, for example,
-// isn't always needed in response and it wounldn't be nice to bloat responses
-// with redundant data.
+// Instead it's written to client by EnvelopeRenderer which takes required fields
+// because error field, for example, isn't always needed in response and
+// it wounldn't be nice to bloat responses with redundant data.
 type Response struct {
 	Successful bool          `json:"successful"`
 	Error      ResponseError `json:"error"`
@@ -19,6 +26,18 @@ type Response struct {
 
 	// EndRequest specifies whether this response should be considered as final.
 	EndRequest bool `json:"-"`
+
+	// Headers are HTTP headers written to client along with response.
+	// Headers set by previous handlers in chain are preserved unless overridden.
+	Headers http.Header `json:"-"`
+
+	// Cookies are set on client along with response.
+	// Cookies set by previous handlers in chain are preserved unless overridden.
+	Cookies []*http.Cookie `json:"-"`
+
+	// err is an error response was created from by Error. It's set only for
+	// errors which don't wrap HTTPError, so that API can map them on responses.
+	err error
 }
 
 // ResponseError describes error information returned in response.
@@ -26,6 +45,10 @@ type ResponseError struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data"`
+
+	// RequestID is an identifier of the request which failed.
+	// It's set by API on every failed response.
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // Ok creates successful response.
@@ -38,6 +61,32 @@ func Ok(data interface{}) *Response {
 	return response
 }
 
+// Created creates successful 201 Created response.
+// Location header is set if location isn't empty.
+func Created(data interface{}, location string) *Response {
+	response := Ok(data)
+	response.HTTPCode = 201
+	if len(location) > 0 {
+		response.WithHeader("Location", location)
+	}
+	return response
+}
+
+// Accepted creates successful 202 Accepted response.
+func Accepted(data interface{}) *Response {
+	response := Ok(data)
+	response.HTTPCode = 202
+	return response
+}
+
+// NoContent creates successful 204 No Content response.
+// Such response is written without body, so it has no envelope.
+func NoContent() *Response {
+	response := Ok(nil)
+	response.HTTPCode = 204
+	return response
+}
+
 // Fail creates unsuccessful response with error information.
 func Fail(code int, data interface{}, errorData ResponseError) *Response {
 	response := &Response{}
@@ -69,6 +118,18 @@ func BadRequestMessage(message string) *Response {
 	return createHTTPErrorResponse(400, message)
 }
 
+// BadRequestError creates 400 Bad Request HTTP response describing error returned
+// by Request.Bind or Validate. In case of *ValidationError every failed field
+// is listed in error data.
+func BadRequestError(err error) *Response {
+	response := BadRequestMessage(err.Error())
+	if validationErr, ok := err.(*ValidationError); ok {
+		response.Error.Message = "Validation failed"
+		response.Error.Data = validationErr.Fields
+	}
+	return response
+}
+
 // Unauthorized creates 401 Unauthorized HTTP response.
 func Unauthorized() *Response {
 	return UnauthorizedMessage("Unauthorized")
@@ -79,9 +140,121 @@ func UnauthorizedMessage(message string) *Response {
 	return createHTTPErrorResponse(401, message)
 }
 
-// Error creates 500 internal error HTTP response.
+// NotFound creates 404 Not Found HTTP response.
+func NotFound() *Response {
+	return NotFoundMessage("Not Found")
+}
+
+// NotFoundMessage creates 404 Not Found HTTP response with specified message.
+func NotFoundMessage(message string) *Response {
+	return createHTTPErrorResponse(404, message)
+}
+
+// MethodNotAllowed creates 405 Method Not Allowed HTTP response.
+func MethodNotAllowed() *Response {
+	return MethodNotAllowedMessage("Method Not Allowed")
+}
+
+// MethodNotAllowedMessage creates 405 Method Not Allowed HTTP response
+// with specified message.
+func MethodNotAllowedMessage(message string) *Response {
+	return createHTTPErrorResponse(405, message)
+}
+
+// Conflict creates 409 Conflict HTTP response.
+func Conflict() *Response {
+	return ConflictMessage("Conflict")
+}
+
+// ConflictMessage creates 409 Conflict HTTP response with specified message.
+func ConflictMessage(message string) *Response {
+	return createHTTPErrorResponse(409, message)
+}
+
+// Gone creates 410 Gone HTTP response.
+func Gone() *Response {
+	return GoneMessage("Gone")
+}
+
+// GoneMessage creates 410 Gone HTTP response with specified message.
+func GoneMessage(message string) *Response {
+	return createHTTPErrorResponse(410, message)
+}
+
+// PreconditionFailed creates 412 Precondition Failed HTTP response.
+func PreconditionFailed() *Response {
+	return PreconditionFailedMessage("Precondition Failed")
+}
+
+// PreconditionFailedMessage creates 412 Precondition Failed HTTP response
+// with specified message.
+func PreconditionFailedMessage(message string) *Response {
+	return createHTTPErrorResponse(412, message)
+}
+
+// UnprocessableEntity creates 422 Unprocessable Entity HTTP response.
+func UnprocessableEntity() *Response {
+	return UnprocessableEntityMessage("Unprocessable Entity")
+}
+
+// UnprocessableEntityMessage creates 422 Unprocessable Entity HTTP response
+// with specified message.
+func UnprocessableEntityMessage(message string) *Response {
+	return createHTTPErrorResponse(422, message)
+}
+
+// TooManyRequests creates 429 Too Many Requests HTTP response.
+// Retry-After header is set in seconds if retryAfter is positive.
+func TooManyRequests(retryAfter time.Duration) *Response {
+	return TooManyRequestsMessage(retryAfter, "Too Many Requests")
+}
+
+// TooManyRequestsMessage creates 429 Too Many Requests HTTP response
+// with specified message. Retry-After header is set in seconds if retryAfter is positive.
+func TooManyRequestsMessage(retryAfter time.Duration, message string) *Response {
+	response := createHTTPErrorResponse(429, message)
+	if retryAfter > 0 {
+		seconds := ceilSeconds(retryAfter)
+		response.WithHeader("Retry-After", strconv.FormatInt(seconds, 10))
+	}
+	return response
+}
+
+// ServiceUnavailable creates 503 Service Unavailable HTTP response.
+func ServiceUnavailable() *Response {
+	return ServiceUnavailableMessage("Service Unavailable")
+}
+
+// ServiceUnavailableMessage creates 503 Service Unavailable HTTP response
+// with specified message.
+func ServiceUnavailableMessage(message string) *Response {
+	return createHTTPErrorResponse(503, message)
+}
+
+// GatewayTimeout creates 504 Gateway Timeout HTTP response.
+func GatewayTimeout() *Response {
+	return GatewayTimeoutMessage("Gateway Timeout")
+}
+
+// GatewayTimeoutMessage creates 504 Gateway Timeout HTTP response
+// with specified message.
+func GatewayTimeoutMessage(message string) *Response {
+	return createHTTPErrorResponse(504, message)
+}
+
+// Error creates HTTP response from error.
+// If error is or wraps HTTPError, its code, message and data are used.
+// Otherwise 500 internal error response is created, unless error matches
+// one of errors registered via API.RegisterError.
 func Error(err error) *Response {
-	return ErrorMessage(err.Error())
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		errorData := ResponseError{Code: httpErr.Code, Message: httpErr.Message, Data: httpErr.Data}
+		return Fail(httpErr.Code, nil, errorData)
+	}
+	response := ErrorMessage(err.Error())
+	response.err = err
+	return response
 }
 
 // ErrorMessage creates 500 internal error HTTP response with specified message.
@@ -98,6 +271,66 @@ func Next(data ...interface{}) *Response {
 	return response
 }
 
+// WithHeader sets HTTP header to be written along with response.
+// Returns the same response allowing to chain calls.
+func (response *Response) WithHeader(name string, value string) *Response {
+	if response.Headers == nil {
+		response.Headers = make(http.Header)
+	}
+	response.Headers.Set(name, value)
+	return response
+}
+
+// WithCookie adds cookie to be set along with response. Cookie with the same name
+// added before is replaced. Returns the same response allowing to chain calls.
+func (response *Response) WithCookie(cookie *http.Cookie) *Response {
+	for i, existing := range response.Cookies {
+		if existing.Name == cookie.Name {
+			response.Cookies[i] = cookie
+			return response
+		}
+	}
+	response.Cookies = append(response.Cookies, cookie)
+	return response
+}
+
+// mergeResponseHeaders returns copy of response with headers and cookies
+// of previous response which aren't set on the response. Response itself isn't
+// changed, since handlers may return the same response to many requests.
+func mergeResponseHeaders(response *Response, prev *Response) *Response {
+	if response == nil || prev == nil || response == prev ||
+		(len(prev.Headers) == 0 && len(prev.Cookies) == 0) {
+		return response
+	}
+	merged := *response
+	merged.Headers = response.Headers.Clone()
+	for name, values := range prev.Headers {
+		if _, ok := merged.Headers[name]; !ok {
+			if merged.Headers == nil {
+				merged.Headers = make(http.Header)
+			}
+			merged.Headers[name] = values
+		}
+	}
+	var cookies []*http.Cookie
+	for _, cookie := range prev.Cookies {
+		if !hasCookie(response.Cookies, cookie.Name) {
+			cookies = append(cookies, cookie)
+		}
+	}
+	merged.Cookies = append(cookies, response.Cookies...)
+	return &merged
+}
+
+func hasCookie(cookies []*http.Cookie, name string) bool {
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func createHTTPErrorResponse(code int, message string) *Response {
 	errorCode := code
 	errorData := ResponseError{Code: errorCode, Message: message}
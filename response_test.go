@@ -6,7 +6,9 @@ package jo
 
 import (
 	"errors"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -80,3 +82,111 @@ func TestError(t *testing.T) {
 	response := http.Get(path)
 	AssertResponseError(t, response, errMessage)
 }
+
+// Creates API with a chain of handlers setting headers and cookies
+// and checks they're merged and written to client.
+func TestResponseHeadersAndCookies(t *testing.T) {
+	api, _, _ := newAPITest()
+	api.SetInitRequestHandler(func(r *Request) *Response {
+		return Next().WithHeader("X-Init", "init").WithCookie(&http.Cookie{Name: "a", Value: "1"})
+	})
+	api.SetEndRequestHandler(func(r *Request) *Response {
+		return Ok(r.PrevHandlerResponse.Data).WithHeader("X-End", "end")
+	})
+	path := "/headers"
+	api.Map("post", path,
+		func(r *Request) *Response {
+			return Next().WithHeader("Cache-Control", "no-cache").
+				WithCookie(&http.Cookie{Name: "session", Value: "old"})
+		},
+		func(r *Request) *Response {
+			return Ok(true).WithHeader("Location", "/orders/1").
+				WithCookie(&http.Cookie{Name: "session", Value: "new"})
+		})
+
+	recorder := recordRequest(api, "POST", path, nil, nil)
+
+	assert.Equal(t, 200, recorder.Code)
+	headers := recorder.Header()
+	assert.Equal(t, "init", headers.Get("X-Init"))
+	assert.Equal(t, "no-cache", headers.Get("Cache-Control"))
+	assert.Equal(t, "/orders/1", headers.Get("Location"))
+	assert.Equal(t, "end", headers.Get("X-End"))
+	assert.Equal(t, []string{"a=1", "session=new"}, headers["Set-Cookie"])
+}
+
+func TestSuccessfulStatuses(t *testing.T) {
+	api, _, http := newAPITest()
+	api.Map("post", "/created", func(r *Request) *Response {
+		return Created(1, "/items/1")
+	})
+	api.Map("post", "/accepted", func(r *Request) *Response {
+		return Accepted(nil)
+	})
+	api.Map("delete", "/no-content", func(r *Request) *Response {
+		return NoContent().WithHeader("X-Deleted", "1")
+	})
+
+	AssertCreated(t, http.Post("/created", nil), "/items/1")
+	AssertAccepted(t, http.Post("/accepted", nil))
+	response := http.Delete("/no-content")
+	AssertNoContent(t, response)
+	assert.Equal(t, "1", response.Headers.Get("X-Deleted"))
+
+	recorder := recordRequest(api, "DELETE", "/no-content", nil, nil)
+	assert.Empty(t, recorder.Body.String())
+}
+
+func TestErrorStatuses(t *testing.T) {
+	api, _, http := newAPITest()
+	responses := map[string]*Response{
+		"/not-found":           NotFound(),
+		"/conflict":            Conflict(),
+		"/gone":                Gone(),
+		"/precondition-failed": PreconditionFailed(),
+		"/unprocessable":       UnprocessableEntity(),
+		"/too-many":            TooManyRequests(1500 * time.Millisecond),
+		"/unavailable":         ServiceUnavailable(),
+		"/conflict-message":    ConflictMessage("Already exists"),
+	}
+	for path, response := range responses {
+		response := response
+		api.Map("get", path, func(r *Request) *Response {
+			return response
+		})
+	}
+
+	AssertNotFound(t, http.Get("/not-found"))
+	AssertConflict(t, http.Get("/conflict"))
+	AssertGone(t, http.Get("/gone"))
+	AssertPreconditionFailed(t, http.Get("/precondition-failed"))
+	AssertUnprocessableEntity(t, http.Get("/unprocessable"))
+	AssertServiceUnavailable(t, http.Get("/unavailable"))
+	AssertConflict(t, http.Get("/conflict-message"), "Already exists")
+	response := http.Get("/too-many")
+	AssertTooManyRequests(t, response)
+	assert.Equal(t, "2", response.Headers.Get("Retry-After"))
+}
+
+// Creates API with handlers returning shared responses. Headers and cookies
+// of previous handlers must not be merged into shared responses.
+func TestSharedResponse(t *testing.T) {
+	api, _, _ := newAPITest()
+	errForbidden := Forbidden()
+	api.Map("get", "/orders",
+		func(r *Request) *Response {
+			return Next().WithCookie(&http.Cookie{Name: "session", Value: r.Context.Query("user")})
+		},
+		func(r *Request) *Response {
+			return errForbidden
+		})
+
+	recorder := recordRequest(api, "GET", "/orders?user=alice", nil, nil)
+	assert.Equal(t, 403, recorder.Code)
+	assert.Equal(t, []string{"session=alice"}, recorder.Header()["Set-Cookie"])
+
+	recorder = recordRequest(api, "GET", "/orders?user=bob", nil, nil)
+	assert.Equal(t, []string{"session=bob"}, recorder.Header()["Set-Cookie"])
+	assert.Empty(t, errForbidden.Cookies)
+	assert.Empty(t, errForbidden.Headers)
+}
@@ -4,6 +4,11 @@
 
 package jo
 
+import (
+	"reflect"
+	"time"
+)
+
 // RouteHandler is a definition of a function which handles request on specific route.
 type RouteHandler func(request *Request) *Response
 
@@ -13,4 +18,59 @@ type Route struct {
 	Method   string
 	Path     string
 	Handlers []RouteHandler
+
+	// Input is a type of request body expected by typed handler created by Handle.
+	// It's recorded by WithTypes option and is nil for other routes.
+	Input reflect.Type
+
+	// Output is a type of response data returned by typed handler created by Handle.
+	// It's recorded by WithTypes option and is nil for other routes.
+	Output reflect.Type
+
+	// Timeout is a maximum duration of request handling on the route.
+	// When it's zero, API-level timeout is used.
+	Timeout time.Duration
+
+	// RequiredScopes are scopes principal must be granted, see RequireScopes.
+	RequiredScopes []string
+
+	// RequiredRoles are roles principal must have one of, see RequireRoles.
+	RequiredRoles []string
+
+	// renderer writes route responses. When not specified
+	// API-level renderer is used.
+	renderer EnvelopeRenderer
+
+	// group is a route group the route was mapped with. It's nil for routes
+	// mapped directly on API.
+	group *Group
+}
+
+// RouteOption is a function which changes route definition.
+// Route options are applied to mapped routes via RouteList.With.
+type RouteOption func(route *Route)
+
+// RouteList is a list of routes created by a single Map call.
+type RouteList []*Route
+
+// With applies route options to every route of the list, e.g.
+//
+//	api.Map("post", "/orders", auth, createOrder).With(RequireScopes("orders:write"))
+//
+// Returns the same list, so calls can be chained.
+func (routes RouteList) With(options ...RouteOption) RouteList {
+	for _, route := range routes {
+		for _, option := range options {
+			option(route)
+		}
+	}
+	return routes
+}
+
+// WithTimeout creates route option which sets maximum duration of request handling.
+// See API.SetTimeout for the details.
+func WithTimeout(timeout time.Duration) RouteOption {
+	return func(route *Route) {
+		route.Timeout = timeout
+	}
 }
@@ -0,0 +1,63 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// SlogLogger adapts standard library structured logger to StructuredLogger.
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger creates StructuredLogger writing to specified slog logger.
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	return &SlogLogger{logger: logger}
+}
+
+// Debug logs formatted message with debug level.
+func (l *SlogLogger) Debug(format string, v ...interface{}) {
+	l.logger.Debug(fmt.Sprintf(format, v...))
+}
+
+// Info logs formatted message with info level.
+func (l *SlogLogger) Info(format string, v ...interface{}) {
+	l.logger.Info(fmt.Sprintf(format, v...))
+}
+
+// Warn logs formatted message with warn level.
+func (l *SlogLogger) Warn(format string, v ...interface{}) {
+	l.logger.Warn(fmt.Sprintf(format, v...))
+}
+
+// Error logs formatted message with error level.
+func (l *SlogLogger) Error(format string, v ...interface{}) {
+	l.logger.Error(fmt.Sprintf(format, v...))
+}
+
+// With returns logger which adds specified fields to every message.
+func (l *SlogLogger) With(fields ...interface{}) StructuredLogger {
+	return &SlogLogger{logger: l.logger.With(fields...)}
+}
+
+// Log logs message with specified level and fields.
+func (l *SlogLogger) Log(level LogLevel, message string, fields ...interface{}) {
+	l.logger.Log(context.Background(), toSlogLevel(level), message, fields...)
+}
+
+func toSlogLevel(level LogLevel) slog.Level {
+	switch level {
+	case DebugLevel:
+		return slog.LevelDebug
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
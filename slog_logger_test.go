@@ -0,0 +1,74 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Parses JSON lines written by slog.
+func readSlogRecords(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		record := make(map[string]interface{})
+		assert.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+// Creates API with slog logger and checks request logger and access log fields.
+func TestSlogLogger(t *testing.T) {
+	api, _, http := newAPITest()
+	buffer := &bytes.Buffer{}
+	options := &slog.HandlerOptions{Level: slog.LevelDebug}
+	api.SetLogger(NewSlogLogger(slog.New(slog.NewJSONHandler(buffer, options))))
+	api.Map("get", "/users/:id", func(request *Request) *Response {
+		request.Logger.Info("Loading user %s", request.Param("id"))
+		logger := request.Logger.(StructuredLogger).With("user_id", 1)
+		logger.Log(WarnLevel, "User is locked", "reason", "spam")
+		return Ok(true)
+	})
+
+	http.Get("/users/1")
+
+	records := readSlogRecords(t, buffer)
+	assert.Equal(t, 3, len(records))
+	assert.Equal(t, "Loading user 1", records[0]["msg"])
+	assert.Equal(t, "/users/:id", records[0]["route"])
+	assert.Equal(t, "GET", records[0]["method"])
+	assert.Equal(t, "WARN", records[1]["level"])
+	assert.Equal(t, float64(1), records[1]["user_id"])
+	assert.Equal(t, "spam", records[1]["reason"])
+	assert.Equal(t, "Request handled", records[2]["msg"])
+	assert.Equal(t, float64(200), records[2]["status"])
+	assert.Equal(t, "true", records[2]["successful"])
+}
+
+// Creates API with slog logger and panicking handler.
+func TestSlogLoggerPanic(t *testing.T) {
+	api, _, http := newAPITest()
+	buffer := &bytes.Buffer{}
+	api.SetLogger(NewSlogLogger(slog.New(slog.NewJSONHandler(buffer, nil))))
+	api.SetAccessLog(AccessLogConfig{Disabled: true})
+	api.Map("get", "/panic", func(request *Request) *Response {
+		panic("boom")
+	})
+
+	http.Get("/panic")
+
+	records := readSlogRecords(t, buffer)
+	assert.Equal(t, 1, len(records))
+	assert.Equal(t, "ERROR", records[0]["level"])
+	assert.Equal(t, "boom", records[0]["panic"])
+	assert.Equal(t, "/panic", records[0]["route"])
+	assert.NotEmpty(t, records[0]["stack"])
+}
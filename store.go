@@ -0,0 +1,36 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+// Set stores value by key making it available to every handler called
+// later on the same request, including end request handler.
+func (request *Request) Set(key string, value interface{}) {
+	request.valuesMutex.Lock()
+	defer request.valuesMutex.Unlock()
+	if request.values == nil {
+		request.values = make(map[string]interface{})
+	}
+	request.values[key] = value
+}
+
+// Get returns value stored by key and whether it exists.
+func (request *Request) Get(key string) (interface{}, bool) {
+	request.valuesMutex.RLock()
+	defer request.valuesMutex.RUnlock()
+	value, exists := request.values[key]
+	return value, exists
+}
+
+// Value returns value of specific type stored on request by key.
+// Returns false if there's no value or it's of another type.
+func Value[T any](request *Request, key string) (T, bool) {
+	var result T
+	value, exists := request.Get(key)
+	if !exists {
+		return result, false
+	}
+	result, ok := value.(T)
+	return result, ok
+}
@@ -0,0 +1,49 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPrincipal struct {
+	Name string
+}
+
+// Creates API where init request handler stores principal,
+// route handler and end request handler read it.
+func TestRequestStore(t *testing.T) {
+	api, _, http := newAPITest()
+	api.SetInitRequestHandler(func(request *Request) *Response {
+		request.Set("principal", &testPrincipal{Name: "admin"})
+		return Next()
+	})
+	api.SetEndRequestHandler(func(request *Request) *Response {
+		principal, ok := Value[*testPrincipal](request, "principal")
+		assert.True(t, ok)
+		return request.PrevHandlerResponse.WithHeader("X-Principal", principal.Name)
+	})
+	api.Map("get", "/me", func(request *Request) *Response {
+		principal, ok := Value[*testPrincipal](request, "principal")
+		assert.True(t, ok)
+
+		_, ok = Value[string](request, "principal")
+		assert.False(t, ok)
+		_, ok = Value[string](request, "missing")
+		assert.False(t, ok)
+		_, exists := request.Get("missing")
+		assert.False(t, exists)
+
+		return Ok(principal.Name)
+	})
+
+	response := http.Get("/me")
+
+	AssertOk(t, response)
+	assert.Equal(t, "admin", response.Data)
+	assert.Equal(t, "admin", response.Headers.Get("X-Principal"))
+}
@@ -4,27 +4,34 @@
 
 package jo
 
-import "log"
+import (
+	"fmt"
+	"log"
+)
 
 type testLogger struct {
+	// messages contains every message logged prefixed with category.
+	messages []string
 }
 
 func (l *testLogger) Debug(format string, v ...interface{}) {
-	l.log("debug", format, v)
+	l.log("debug", format, v...)
 }
 
 func (l *testLogger) Info(format string, v ...interface{}) {
-	l.log("info", format, v)
+	l.log("info", format, v...)
 }
 
 func (l *testLogger) Warn(format string, v ...interface{}) {
-	l.log("warn", format, v)
+	l.log("warn", format, v...)
 }
 
 func (l *testLogger) Error(format string, v ...interface{}) {
-	l.log("error", format, v)
+	l.log("error", format, v...)
 }
 
 func (l *testLogger) log(category string, format string, v ...interface{}) {
-	log.Printf("["+category+"] "+format, v)
+	message := fmt.Sprintf("["+category+"] "+format, v...)
+	l.messages = append(l.messages, message)
+	log.Print(message)
 }
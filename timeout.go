@@ -0,0 +1,127 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"gopkg.in/gin-gonic/gin.v1"
+)
+
+// timeoutWriter buffers everything handlers write while they run with timeout.
+// Buffered output is written to client only when handlers finish in time,
+// otherwise it's discarded. It doesn't keep reference to client writer,
+// so handlers which outlive timeout can't touch it.
+type timeoutWriter struct {
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	written     bool
+	closeNotify chan bool
+}
+
+// newTimeoutWriter creates writer with headers and status copied from specified writer.
+// Its close notification fires when ctx is done.
+func newTimeoutWriter(ctx context.Context, writer gin.ResponseWriter) *timeoutWriter {
+	timeoutWriter := &timeoutWriter{
+		header:      writer.Header().Clone(),
+		status:      writer.Status(),
+		closeNotify: make(chan bool, 1),
+	}
+	go func() {
+		<-ctx.Done()
+		timeoutWriter.closeNotify <- true
+	}()
+	return timeoutWriter
+}
+
+// Header returns buffered headers.
+func (writer *timeoutWriter) Header() http.Header {
+	return writer.header
+}
+
+// WriteHeader sets buffered status.
+func (writer *timeoutWriter) WriteHeader(code int) {
+	if code > 0 && !writer.written {
+		writer.status = code
+	}
+}
+
+// WriteHeaderNow marks headers as written.
+func (writer *timeoutWriter) WriteHeaderNow() {
+	writer.written = true
+}
+
+// Write appends data to buffered body.
+func (writer *timeoutWriter) Write(data []byte) (int, error) {
+	writer.written = true
+	return writer.body.Write(data)
+}
+
+// WriteString appends string to buffered body.
+func (writer *timeoutWriter) WriteString(data string) (int, error) {
+	writer.written = true
+	return writer.body.WriteString(data)
+}
+
+// Status returns buffered status.
+func (writer *timeoutWriter) Status() int {
+	return writer.status
+}
+
+// Size returns size of buffered body or -1 if nothing was written.
+func (writer *timeoutWriter) Size() int {
+	if !writer.written {
+		return -1
+	}
+	return writer.body.Len()
+}
+
+// Written returns whether headers or body were written.
+func (writer *timeoutWriter) Written() bool {
+	return writer.written
+}
+
+// Flush does nothing, since output is buffered until handlers finish.
+func (writer *timeoutWriter) Flush() {
+}
+
+// Hijack isn't supported while handlers run with timeout.
+func (writer *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("jo: connection can't be hijacked by handlers with timeout")
+}
+
+// CloseNotify returns channel which receives true when handlers' context is done.
+func (writer *timeoutWriter) CloseNotify() <-chan bool {
+	return writer.closeNotify
+}
+
+// Pusher returns nil, since server push isn't supported while handlers run with timeout.
+func (writer *timeoutWriter) Pusher() http.Pusher {
+	return nil
+}
+
+// writeTo writes buffered headers, status and body to specified writer.
+func (writer *timeoutWriter) writeTo(target gin.ResponseWriter) {
+	header := target.Header()
+	for name := range header {
+		if _, ok := writer.header[name]; !ok {
+			header.Del(name)
+		}
+	}
+	for name, values := range writer.header {
+		header[name] = values
+	}
+	target.WriteHeader(writer.status)
+	if writer.written {
+		target.WriteHeaderNow()
+		target.Write(writer.body.Bytes())
+	}
+}
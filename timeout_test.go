@@ -0,0 +1,101 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Waits for request context or returns Ok after a while.
+func waitHandler(duration time.Duration) RouteHandler {
+	return func(request *Request) *Response {
+		select {
+		case <-time.After(duration):
+			return Ok(true)
+		case <-request.Ctx().Done():
+			return Error(request.Ctx().Err())
+		}
+	}
+}
+
+// Creates API with global timeout and a route overriding it.
+func TestTimeout(t *testing.T) {
+	api, _, http := newAPITest()
+	api.SetTimeout(10 * time.Millisecond)
+	api.Map("get", "/slow", waitHandler(time.Second))
+	api.Map("get", "/fast", waitHandler(time.Millisecond))
+	api.Map("get", "/patient", waitHandler(20*time.Millisecond)).With(WithTimeout(time.Second))
+
+	AssertGatewayTimeout(t, http.Get("/slow"))
+	AssertOk(t, http.Get("/fast"))
+	AssertOk(t, http.Get("/patient"))
+	assert.Equal(t, time.Second, api.Routes()[2].Timeout)
+}
+
+// Creates API without timeout and checks request context and context errors.
+func TestRequestContext(t *testing.T) {
+	api, _, http := newAPITest()
+	api.Map("get", "/ctx", func(request *Request) *Response {
+		assert.NotNil(t, request.Ctx())
+		assert.NoError(t, request.Ctx().Err())
+		return Ok(true)
+	})
+	api.Map("get", "/deadline", func(request *Request) *Response {
+		return Error(context.DeadlineExceeded)
+	})
+	api.Map("get", "/canceled", func(request *Request) *Response {
+		return Error(context.Canceled)
+	})
+
+	AssertOk(t, http.Get("/ctx"))
+	AssertGatewayTimeout(t, http.Get("/deadline"))
+	AssertServiceUnavailable(t, http.Get("/canceled"))
+}
+
+// Creates API with timeout and handler which ignores request context and keeps
+// using request and its writer after deadline. Its output must be discarded.
+func TestTimeoutHandlerIgnoringContext(t *testing.T) {
+	api, _, _ := newAPITest()
+	api.SetTimeout(10 * time.Millisecond)
+	finished := make(chan bool)
+	api.SetEndRequestHandler(func(request *Request) *Response {
+		_, late := request.Get("late")
+		assert.False(t, late)
+		return request.PrevHandlerResponse
+	})
+	api.Map("get", "/stubborn", func(request *Request) *Response {
+		time.Sleep(50 * time.Millisecond)
+		assert.True(t, <-request.Context.Writer.CloseNotify())
+		request.Context.Writer.Header().Set("X-Late", "1")
+		request.Context.Writer.WriteString("late")
+		request.Context.Writer.Flush()
+		request.Set("late", true)
+		close(finished)
+		return Ok(true).WithHeader("X-Late-Response", "1")
+	})
+	api.Map("get", "/in-time", func(request *Request) *Response {
+		request.Context.Writer.Header().Set("X-In-Time", "1")
+		request.Set("in-time", true)
+		return Next(nil)
+	}, func(request *Request) *Response {
+		inTime, _ := Value[bool](request, "in-time")
+		return Ok(inTime)
+	})
+
+	recorder := recordRequest(api, "GET", "/stubborn", nil, nil)
+	<-finished
+	assert.Equal(t, 504, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("X-Late"))
+	assert.Empty(t, recorder.Header().Get("X-Late-Response"))
+
+	recorder = recordRequest(api, "GET", "/in-time", nil, nil)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "1", recorder.Header().Get("X-In-Time"))
+	assert.Contains(t, recorder.Body.String(), `"data":true`)
+}
@@ -0,0 +1,132 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// ClientCertMode is a policy of client certificate authentication.
+type ClientCertMode int
+
+const (
+	// NoClientCert doesn't request client certificates.
+	NoClientCert ClientCertMode = iota
+
+	// VerifyClientCertIfGiven verifies client certificates when they're presented,
+	// clients without certificate are allowed.
+	VerifyClientCertIfGiven
+
+	// RequireClientCert requires every client to present valid certificate.
+	RequireClientCert
+)
+
+// TLSOptions configures TLS of API started by RunTLS.
+type TLSOptions struct {
+	// ClientCertMode is a policy of client certificate authentication.
+	ClientCertMode ClientCertMode
+
+	// ClientCAFile is a path to PEM bundle of CA certificates client
+	// certificates are verified against. It's required when client certificates
+	// are verified.
+	ClientCAFile string
+
+	// Config is a base TLS configuration, e.g. with minimal TLS version.
+	// It isn't modified.
+	Config *tls.Config
+}
+
+// PeerCertificate is a verified client certificate of the request.
+type PeerCertificate struct {
+	// Subject is a distinguished name of the certificate subject.
+	Subject string `json:"subject"`
+
+	// CommonName is a common name of the certificate subject.
+	CommonName string `json:"common_name"`
+
+	// DNSNames, EmailAddresses, IPAddresses and URIs are subject alternative names.
+	DNSNames       []string `json:"dns_names,omitempty"`
+	EmailAddresses []string `json:"email_addresses,omitempty"`
+	IPAddresses    []string `json:"ip_addresses,omitempty"`
+	URIs           []string `json:"uris,omitempty"`
+
+	// Fingerprint is a hex-encoded SHA-256 hash of the certificate.
+	Fingerprint string `json:"fingerprint"`
+
+	// Certificate is the parsed certificate. It isn't serialized.
+	Certificate *x509.Certificate `json:"-"`
+}
+
+// SetTLSOptions configures TLS of API started by RunTLS, e.g. to authenticate
+// clients with certificates.
+func (api *API) SetTLSOptions(options TLSOptions) {
+	api.tlsOptions = options
+}
+
+// PeerCertificate returns client certificate verified during TLS handshake
+// or nil if client didn't present one or it wasn't verified.
+func (request *Request) PeerCertificate() *PeerCertificate {
+	connectionState := request.Context.Request.TLS
+	if connectionState == nil || len(connectionState.VerifiedChains) == 0 ||
+		len(connectionState.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return newPeerCertificate(connectionState.VerifiedChains[0][0])
+}
+
+func newPeerCertificate(certificate *x509.Certificate) *PeerCertificate {
+	fingerprint := sha256.Sum256(certificate.Raw)
+	peer := &PeerCertificate{
+		Subject:        certificate.Subject.String(),
+		CommonName:     certificate.Subject.CommonName,
+		DNSNames:       certificate.DNSNames,
+		EmailAddresses: certificate.EmailAddresses,
+		Fingerprint:    hex.EncodeToString(fingerprint[:]),
+		Certificate:    certificate,
+	}
+	for _, ip := range certificate.IPAddresses {
+		peer.IPAddresses = append(peer.IPAddresses, ip.String())
+	}
+	for _, uri := range certificate.URIs {
+		peer.URIs = append(peer.URIs, uri.String())
+	}
+	return peer
+}
+
+// createTLSConfig creates TLS configuration from TLS options.
+// Returns nil when there are no options.
+func (api *API) createTLSConfig() (*tls.Config, error) {
+	options := api.tlsOptions
+	if options.Config == nil && options.ClientCertMode == NoClientCert {
+		return nil, nil
+	}
+	config := &tls.Config{}
+	if options.Config != nil {
+		config = options.Config.Clone()
+	}
+	if options.ClientCertMode == NoClientCert {
+		return config, nil
+	}
+
+	pemBytes, err := os.ReadFile(options.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(pemBytes) {
+		return nil, fmt.Errorf("No certificates found in %s", options.ClientCAFile)
+	}
+	config.ClientCAs = clientCAs
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	if options.ClientCertMode == VerifyClientCertIfGiven {
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return config, nil
+}
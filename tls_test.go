@@ -0,0 +1,77 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates TLS configuration from options.
+func TestCreateTLSConfig(t *testing.T) {
+	api := NewAPI()
+	config, err := api.createTLSConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, config)
+
+	base := &tls.Config{MinVersion: tls.VersionTLS12}
+	api.SetTLSOptions(TLSOptions{ClientCertMode: VerifyClientCertIfGiven, ClientCAFile: inTestCRTFile, Config: base})
+	config, err = api.createTLSConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, tls.VerifyClientCertIfGiven, config.ClientAuth)
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	assert.NotNil(t, config.ClientCAs)
+	assert.Nil(t, base.ClientCAs)
+
+	api.SetTLSOptions(TLSOptions{ClientCertMode: RequireClientCert, ClientCAFile: "test_files/missing.crt"})
+	_, err = api.createTLSConfig()
+	assert.Error(t, err)
+	api.SetTLSOptions(TLSOptions{ClientCertMode: RequireClientCert, ClientCAFile: inTestKeyFile})
+	_, err = api.createTLSConfig()
+	assert.Error(t, err)
+	assert.Error(t, api.RunTLS(inTestHostTLS, inTestCRTFile, inTestKeyFile))
+}
+
+// Checks peer certificate is taken from verified chain only.
+func TestPeerCertificate(t *testing.T) {
+	pemBytes, err := os.ReadFile(inTestCRTFile)
+	assert.NoError(t, err)
+	block, _ := pem.Decode(pemBytes)
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	assert.NoError(t, err)
+
+	api, _, _ := newAPITest()
+	var peers []*PeerCertificate
+	api.Map("get", "/", func(request *Request) *Response {
+		peers = append(peers, request.PeerCertificate())
+		return Ok(nil)
+	})
+	engine := api.buildEngine()
+	for _, state := range []*tls.ConnectionState{
+		nil,
+		{PeerCertificates: []*x509.Certificate{certificate}},
+		{VerifiedChains: [][]*x509.Certificate{{certificate}}},
+	} {
+		request := createHTTPTestRequest("GET", "/", nil)
+		request.TLS = state
+		engine.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	assert.Nil(t, peers[0])
+	assert.Nil(t, peers[1])
+	fingerprint := sha256.Sum256(certificate.Raw)
+	assert.Equal(t, hex.EncodeToString(fingerprint[:]), peers[2].Fingerprint)
+	assert.Equal(t, "Slavik", peers[2].CommonName)
+	assert.Contains(t, peers[2].Subject, "O=Slavik")
+	assert.Empty(t, peers[2].DNSNames)
+}
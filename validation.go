@@ -0,0 +1,254 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidationError describes a list of fields which failed validation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// FieldError describes validation failure of a single field.
+// Reason is a machine-readable name of the rule which failed e.g. "required" or "max".
+// Param is a parameter of the rule if it has one.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+	Param  string `json:"param,omitempty"`
+}
+
+// Error returns description of every failed field.
+func (err *ValidationError) Error() string {
+	descriptions := make([]string, 0, len(err.Fields))
+	for _, field := range err.Fields {
+		description := field.Field + ": " + field.Reason
+		if len(field.Param) > 0 {
+			description += "=" + field.Param
+		}
+		descriptions = append(descriptions, description)
+	}
+	return "Validation failed: " + strings.Join(descriptions, ", ")
+}
+
+// Validate checks struct fields against rules specified in "validate" tags.
+// Rules are separated by comma:
+//
+//	required   value must not be zero (non-empty string, slice, non-nil pointer etc.)
+//	omitempty  following rules are skipped for zero value
+//	min=N      minimum number value or minimum length of string, slice or map
+//	max=N      maximum number value or maximum length of string, slice or map
+//	len=N      exact length of string, slice or map
+//	enum=a|b   value must be one of listed
+//	regex=EXP  string must match regular expression; must be the last rule
+//	           because expression may contain commas
+//
+// Rules other than required are skipped for nil pointers.
+// Nested structs, pointers to structs and slices of structs are validated recursively.
+// Field names are taken from "json" tags when specified.
+// Returns *ValidationError listing every failed field or nil.
+// Panics on malformed rules since they are programming errors.
+func Validate(value interface{}) error {
+	return validateFields(value, "json", nil)
+}
+
+// validateFields validates value appending failed fields to the ones specified.
+// Field names are taken from tag specified by nameTag.
+func validateFields(value interface{}, nameTag string, fieldErrors []FieldError) error {
+	validateValue(reflect.ValueOf(value), "", nameTag, &fieldErrors)
+	if len(fieldErrors) > 0 {
+		return &ValidationError{Fields: fieldErrors}
+	}
+	return nil
+}
+
+// validateValue walks through a value looking for structs to validate.
+func validateValue(
+	value reflect.Value, path string, nameTag string, fieldErrors *[]FieldError) {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !value.IsNil() {
+			validateValue(value.Elem(), path, nameTag, fieldErrors)
+		}
+	case reflect.Struct:
+		validateStruct(value, path, nameTag, fieldErrors)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			validateValue(value.Index(i), itemPath, nameTag, fieldErrors)
+		}
+	}
+}
+
+func validateStruct(
+	value reflect.Value, path string, nameTag string, fieldErrors *[]FieldError) {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		fieldPath := joinFieldPath(path, fieldName(field, nameTag))
+		fieldValue := value.Field(i)
+		if hasFieldError(*fieldErrors, fieldPath) {
+			continue
+		}
+		for _, rule := range parseRules(field.Tag.Get("validate")) {
+			if rule.name == "omitempty" {
+				if fieldValue.IsZero() {
+					break
+				}
+				continue
+			}
+			if !rule.check(fieldValue) {
+				*fieldErrors = append(*fieldErrors,
+					FieldError{Field: fieldPath, Reason: rule.name, Param: rule.param})
+				break
+			}
+		}
+		validateValue(fieldValue, fieldPath, nameTag, fieldErrors)
+	}
+}
+
+// hasFieldError checks whether field has already failed validation.
+func hasFieldError(fieldErrors []FieldError, field string) bool {
+	for _, fieldError := range fieldErrors {
+		if fieldError.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldName returns name of a field as specified in tag e.g. "json".
+func fieldName(field reflect.StructField, nameTag string) string {
+	name := strings.Split(field.Tag.Get(nameTag), ",")[0]
+	if len(name) == 0 || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+func joinFieldPath(path string, name string) string {
+	if len(path) == 0 {
+		return name
+	}
+	return path + "." + name
+}
+
+// validationRule is a single rule parsed from validate tag.
+type validationRule struct {
+	name  string
+	param string
+}
+
+func parseRules(tag string) []validationRule {
+	var rules []validationRule
+	for len(tag) > 0 {
+		var ruleStr string
+		if strings.HasPrefix(tag, "regex=") {
+			ruleStr, tag = tag, ""
+		} else if i := strings.Index(tag, ","); i >= 0 {
+			ruleStr, tag = tag[:i], tag[i+1:]
+		} else {
+			ruleStr, tag = tag, ""
+		}
+		rule := validationRule{}
+		rule.name = strings.TrimSpace(ruleStr)
+		if i := strings.Index(ruleStr, "="); i >= 0 {
+			rule.name, rule.param = strings.TrimSpace(ruleStr[:i]), ruleStr[i+1:]
+		}
+		if len(rule.name) > 0 {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// check returns true when value satisfies the rule.
+func (rule validationRule) check(value reflect.Value) bool {
+	if rule.name == "required" {
+		return !value.IsZero()
+	}
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return true
+		}
+		value = value.Elem()
+	}
+	switch rule.name {
+	case "min":
+		return compareMeasure(value, rule) >= 0
+	case "max":
+		return compareMeasure(value, rule) <= 0
+	case "len":
+		return compareMeasure(value, rule) == 0
+	case "enum":
+		actual := fmt.Sprint(value.Interface())
+		for _, option := range strings.Split(rule.param, "|") {
+			if actual == option {
+				return true
+			}
+		}
+		return false
+	case "regex":
+		if value.Kind() != reflect.String {
+			panic(fmt.Sprintf("jo: regex rule can't be applied to %s", value.Kind()))
+		}
+		return compileRegex(rule.param).MatchString(value.String())
+	}
+	panic(fmt.Sprintf("jo: unknown validation rule %q", rule.name))
+}
+
+// compareMeasure compares number value or length of value with rule parameter.
+// Returns -1, 0 or 1 if value is less, equal or greater than parameter.
+func compareMeasure(value reflect.Value, rule validationRule) int {
+	limit, err := strconv.ParseFloat(rule.param, 64)
+	if err != nil {
+		panic(fmt.Sprintf("jo: invalid %s rule parameter %q", rule.name, rule.param))
+	}
+	var actual float64
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = value.Float()
+	case reflect.String:
+		actual = float64(utf8.RuneCountInString(value.String()))
+	case reflect.Slice, reflect.Array, reflect.Map:
+		actual = float64(value.Len())
+	default:
+		panic(fmt.Sprintf("jo: %s rule can't be applied to %s", rule.name, value.Kind()))
+	}
+	switch {
+	case actual < limit:
+		return -1
+	case actual > limit:
+		return 1
+	}
+	return 0
+}
+
+// compiledRegexes caches regular expressions used in validation rules.
+var compiledRegexes sync.Map
+
+func compileRegex(expr string) *regexp.Regexp {
+	if cached, ok := compiledRegexes.Load(expr); ok {
+		return cached.(*regexp.Regexp)
+	}
+	compiled := regexp.MustCompile(expr)
+	compiledRegexes.Store(expr, compiled)
+	return compiled
+}
@@ -0,0 +1,105 @@
+//
+// Copyright (c) 2016 by Viacheslav Shynkarenko. All Rights Reserved.
+//
+
+package jo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testAddress struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip" validate:"len=5,regex=^[0-9]+$"`
+}
+
+type testOrder struct {
+	Name     string         `json:"name" validate:"required,min=2,max=10"`
+	Quantity int            `json:"quantity" validate:"min=1,max=100"`
+	Status   string         `json:"status" validate:"enum=new|paid"`
+	Tags     []string       `json:"tags" validate:"max=2"`
+	Address  *testAddress   `json:"address" validate:"required"`
+	Extra    []testAddress  `json:"extra"`
+	Notes    string         `validate:"regex=^[a-z, ]*$"`
+	Ignored  map[string]int `json:"-"`
+}
+
+func newValidTestOrder() *testOrder {
+	return &testOrder{
+		Name:     "Order",
+		Quantity: 5,
+		Status:   "paid",
+		Tags:     []string{"a"},
+		Address:  &testAddress{City: "Kyiv", Zip: "01001"},
+		Notes:    "fast, please",
+	}
+}
+
+// Validates correct struct.
+func TestValidateValid(t *testing.T) {
+	assert.NoError(t, Validate(newValidTestOrder()))
+}
+
+// Validates struct where every field fails its rules.
+func TestValidateInvalid(t *testing.T) {
+	order := &testOrder{
+		Name:     "O",
+		Quantity: 1000,
+		Status:   "lost",
+		Tags:     []string{"a", "b", "c"},
+		Extra:    []testAddress{{Zip: "abcde"}},
+		Notes:    "UPPER",
+	}
+
+	err := Validate(order)
+
+	assert.IsType(t, &ValidationError{}, err)
+	expected := []FieldError{
+		{Field: "name", Reason: "min", Param: "2"},
+		{Field: "quantity", Reason: "max", Param: "100"},
+		{Field: "status", Reason: "enum", Param: "new|paid"},
+		{Field: "tags", Reason: "max", Param: "2"},
+		{Field: "address", Reason: "required"},
+		{Field: "extra[0].city", Reason: "required"},
+		{Field: "extra[0].zip", Reason: "regex", Param: "^[0-9]+$"},
+		{Field: "Notes", Reason: "regex", Param: "^[a-z, ]*$"},
+	}
+	assert.Equal(t, expected, err.(*ValidationError).Fields)
+}
+
+// Makes sure malformed rules cause panic.
+func TestValidateMalformedRules(t *testing.T) {
+	assert.Panics(t, func() {
+		Validate(&struct {
+			A string `validate:"unknown"`
+		}{A: "a"})
+	})
+	assert.Panics(t, func() {
+		Validate(&struct {
+			A string `validate:"min=abc"`
+		}{A: "a"})
+	})
+}
+
+// Validates zero values. Rules must be applied to them unless omitempty is specified.
+func TestValidateZeroValues(t *testing.T) {
+	err := Validate(&struct {
+		Quantity int    `validate:"min=1"`
+		Status   string `validate:"enum=new|paid"`
+		Optional int    `validate:"omitempty,min=1"`
+		Zip      string `validate:"omitempty,len=5"`
+	}{})
+
+	expected := []FieldError{
+		{Field: "Quantity", Reason: "min", Param: "1"},
+		{Field: "Status", Reason: "enum", Param: "new|paid"},
+	}
+	assert.Equal(t, expected, err.(*ValidationError).Fields)
+
+	err = Validate(&struct {
+		Zip string `validate:"omitempty,len=5"`
+	}{Zip: "123"})
+	assert.Equal(t, []FieldError{{Field: "Zip", Reason: "len", Param: "5"}}, err.(*ValidationError).Fields)
+}